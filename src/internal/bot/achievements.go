@@ -0,0 +1,437 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	achievementCentury      = "century"
+	achievementFirstCentury = "first-century"
+	achievementStreak       = "streak-7"
+	achievementCollector    = "collector-50"
+	// achievementTopReactor - Suffixed with the month, e.g. top-reactor:2026-09
+	achievementTopReactor = "top-reactor"
+
+	centuryReactions   = 100
+	streakDays         = 7
+	collectorEmojis    = 50
+	topReactorMinUsage = 10
+)
+
+type achievementRule struct {
+	Key         string
+	Name        string
+	Description string
+}
+
+// achievementRules - Everything that can be earned, in the order /achievements lists them
+var achievementRules = []achievementRule{
+	{achievementFirstCentury, "First to 100", fmt.Sprintf("First in the server to react %d times", centuryReactions)},
+	{achievementCentury, "Centurion", fmt.Sprintf("Reacted %d times", centuryReactions)},
+	{achievementStreak, "On a roll", fmt.Sprintf("Reacted every day for %d days", streakDays)},
+	{achievementCollector, "Collector", fmt.Sprintf("Used %d different emojis", collectorEmojis)},
+	{achievementTopReactor, "Top reactor", "Most reactions in a month"},
+}
+
+// achievementIdleTTL - Users who haven't reacted for this long are dropped from the cache
+const achievementIdleTTL = time.Hour
+
+// userAchievements - Cached progress for one user, so most reactions need no queries
+type userAchievements struct {
+	mutex  sync.Mutex
+	earned map[string]bool
+	// skipActivity - Reactions left before century or collector could possibly be reached
+	skipActivity int64
+	// streakCheckedOn - Guild local day the streak was last checked, it can only change once a day
+	streakCheckedOn string
+	lastSeen        time.Time
+}
+
+// guildAchievements - Per guild state shared by its users
+type guildAchievements struct {
+	mutex    sync.Mutex
+	location *time.Location
+	// monthChecked - The last month top reactor was settled for
+	monthChecked string
+}
+
+type Achievements struct {
+	// users map[GuildID:UserID]*userAchievements, filled lazily and pruned when idle
+	users  map[string]*userAchievements
+	guilds map[string]*guildAchievements
+	// mutex - Only guards the maps, each entry has its own lock for the slow checks
+	mutex     sync.Mutex
+	lastPrune time.Time
+}
+
+var achievements = &Achievements{
+	users:  make(map[string]*userAchievements),
+	guilds: make(map[string]*guildAchievements),
+}
+
+// achievementRuleFor - Rule for a stored key, monthly keys share a rule
+func achievementRuleFor(key string) achievementRule {
+	base, _, _ := strings.Cut(key, ":")
+	for _, rule := range achievementRules {
+		if rule.Key == base {
+			return rule
+		}
+	}
+
+	return achievementRule{Key: key, Name: key}
+}
+
+// entries - Cache entries for a guild and user, creating them on first use and pruning idle users
+func (a *Achievements) entries(guildID, userID string) (*guildAchievements, *userAchievements) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.lastPrune) >= achievementIdleTTL {
+		for key, user := range a.users {
+			if user.mutex.TryLock() {
+				if now.Sub(user.lastSeen) >= achievementIdleTTL {
+					delete(a.users, key)
+				}
+				user.mutex.Unlock()
+			}
+		}
+		a.lastPrune = now
+	}
+
+	guild, ok := a.guilds[guildID]
+	if !ok {
+		guild = &guildAchievements{}
+		a.guilds[guildID] = guild
+	}
+
+	user, ok := a.users[guildID+":"+userID]
+	if !ok {
+		user = &userAchievements{}
+		a.users[guildID+":"+userID] = user
+	}
+
+	return guild, user
+}
+
+// cachedUser - Cache entry for a user if they have one
+func (a *Achievements) cachedUser(guildID, userID string) (*userAchievements, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	user, ok := a.users[guildID+":"+userID]
+	return user, ok
+}
+
+// forgetLocation - Reload the guild's timezone on the next check
+func (a *Achievements) forgetLocation(guildID string) {
+	a.mutex.Lock()
+	guild, ok := a.guilds[guildID]
+	a.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	guild.mutex.Lock()
+	guild.location = nil
+	guild.mutex.Unlock()
+}
+
+// locationFor - Cached guild timezone
+func (g *guildAchievements) locationFor(guildID string) *time.Location {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.location == nil {
+		g.location = guildLocation(guildID)
+	}
+
+	return g.location
+}
+
+// check - Evaluate every rule after a reaction was logged
+func (a *Achievements) check(guildID, userID string) {
+	guild, user := a.entries(guildID, userID)
+	location := guild.locationFor(guildID)
+
+	user.mutex.Lock()
+	defer func() {
+		user.lastSeen = time.Now()
+		user.mutex.Unlock()
+		a.checkTopReactor(guildID, guild, location)
+	}()
+
+	if user.earned == nil {
+		awarded, err := b.Db.GetAchievementsForUser(guildID, userID)
+		if err != nil {
+			slog.Error("Failed to load achievements", "err", err, "guild_id", guildID, "user_id", userID)
+			return
+		}
+
+		user.earned = make(map[string]bool, len(awarded))
+		for _, achievement := range awarded {
+			user.earned[achievement.Key] = true
+		}
+	}
+	earned := user.earned
+
+	award := func(key string, unique bool) {
+		var ok bool
+		var err error
+		if unique {
+			ok, err = b.Db.AwardUniqueAchievement(guildID, userID, key)
+		} else {
+			ok, err = b.Db.AwardAchievement(guildID, userID, key)
+		}
+		if err != nil {
+			slog.Error("Failed to award achievement", "err", err, "guild_id", guildID, "user_id", userID, "achievement", key)
+			return
+		}
+
+		earned[key] = true
+		if ok {
+			announceAchievement(guildID, userID, key)
+		}
+	}
+
+	// Each reaction adds at most one to the total and one distinct emoji, so skip until a threshold could be hit
+	if user.skipActivity > 0 {
+		user.skipActivity--
+	} else if !earned[achievementCentury] || !earned[achievementCollector] {
+		activity, err := b.Db.GetUserActivityForGuild(guildID, userID)
+		if err != nil {
+			slog.Error("Failed to get user activity", "err", err, "guild_id", guildID, "user_id", userID)
+			return
+		}
+
+		if activity.Total >= centuryReactions && !earned[achievementCentury] {
+			award(achievementFirstCentury, true)
+			award(achievementCentury, false)
+		}
+		if activity.Distinct >= collectorEmojis && !earned[achievementCollector] {
+			award(achievementCollector, false)
+		}
+
+		user.skipActivity = 0
+		if !earned[achievementCentury] {
+			user.skipActivity = centuryReactions - activity.Total - 1
+		}
+		if !earned[achievementCollector] && (earned[achievementCentury] || collectorEmojis-activity.Distinct-1 < user.skipActivity) {
+			user.skipActivity = collectorEmojis - activity.Distinct - 1
+		}
+	}
+
+	today := time.Now().In(location).Format("2006-01-02")
+	if !earned[achievementStreak] && user.streakCheckedOn != today {
+		hourly, err := b.Db.GetHourlyUsageForGuild(guildID, "", userID, "", streakDays+1)
+		if err != nil {
+			slog.Error("Failed to get hourly usage", "err", err, "guild_id", guildID, "user_id", userID)
+			return
+		}
+
+		user.streakCheckedOn = today
+		if reactionStreak(hourly, time.Now(), location) >= streakDays {
+			award(achievementStreak, false)
+		}
+	}
+}
+
+// checkTopReactor - Settle last month's top reactor on the first reaction of a new month
+func (a *Achievements) checkTopReactor(guildID string, guild *guildAchievements, location *time.Location) {
+	now := time.Now().In(location)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	previous := monthStart.AddDate(0, -1, 0)
+	key := fmt.Sprintf("%s:%s", achievementTopReactor, previous.Format("2006-01"))
+
+	guild.mutex.Lock()
+	defer guild.mutex.Unlock()
+	if guild.monthChecked == key {
+		return
+	}
+
+	settled, err := b.Db.HasGuildAchievement(guildID, key)
+	if err != nil {
+		slog.Error("Failed to check top reactor", "err", err, "guild_id", guildID)
+		return
+	}
+	guild.monthChecked = key
+	if settled {
+		return
+	}
+
+	top, err := b.Db.GetTopUserForGuildBetween(guildID, previous, monthStart)
+	if err != nil {
+		slog.Error("Failed to get top reactor", "err", err, "guild_id", guildID)
+		return
+	}
+	if top.Count < topReactorMinUsage {
+		return
+	}
+
+	ok, err := b.Db.AwardUniqueAchievement(guildID, top.EmojiID, key)
+	if err != nil {
+		slog.Error("Failed to award top reactor", "err", err, "guild_id", guildID)
+		return
+	}
+	if user, cached := a.cachedUser(guildID, top.EmojiID); cached {
+		user.mutex.Lock()
+		if user.earned != nil {
+			user.earned[key] = true
+		}
+		user.mutex.Unlock()
+	}
+	if ok {
+		announceAchievement(guildID, top.EmojiID, key)
+	}
+}
+
+// reactionStreak - Consecutive days up to and including today with at least one reaction
+func reactionStreak(hourly map[time.Time]int64, now time.Time, location *time.Location) int {
+	days := make(map[string]bool)
+	for hour, count := range hourly {
+		if count > 0 {
+			days[hour.In(location).Format("2006-01-02")] = true
+		}
+	}
+
+	streak := 0
+	day := now.In(location)
+	for days[day.Format("2006-01-02")] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return streak
+}
+
+// achievementLabel - Name of an achievement, with the month for monthly ones
+func achievementLabel(key string) string {
+	rule := achievementRuleFor(key)
+	if _, month, ok := strings.Cut(key, ":"); ok {
+		if t, err := time.Parse("2006-01", month); err == nil {
+			return fmt.Sprintf("%s of %s", rule.Name, t.Format("January 2006"))
+		}
+	}
+
+	return rule.Name
+}
+
+// announceAchievement - Post a new achievement to the guild's announcement channel, if it has one
+func announceAchievement(guildID, userID, key string) {
+	channelID, err := b.Db.GetGuildAchievementChannel(guildID)
+	if err != nil {
+		slog.Error("Failed to get achievement channel", "err", err, "guild_id", guildID)
+		return
+	}
+	if channelID == "" {
+		return
+	}
+
+	_, err = b.DiscordSession.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:         fmt.Sprintf(":trophy: <@%s> earned **%s**: %s", userID, achievementLabel(key), achievementRuleFor(key).Description),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		slog.Error("Failed to announce achievement", "err", err, "guild_id", guildID, "channel_id", channelID)
+	}
+}
+
+// showAchievements - List the achievements a user has earned and what's left
+func showAchievements(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	userID := ""
+	if i.Member != nil && i.Member.User != nil {
+		userID = i.Member.User.ID
+	}
+	if opt, ok := optionMap["user"]; ok {
+		userID = opt.UserValue(nil).ID
+	}
+
+	awarded, err := b.Db.GetAchievementsForUser(i.GuildID, userID)
+	if err != nil {
+		return fmt.Errorf("getting achievements: %w", err)
+	}
+
+	activity, err := b.Db.GetUserActivityForGuild(i.GuildID, userID)
+	if err != nil {
+		slog.Error("Error getting user activity", "err", err)
+	}
+
+	hourly, err := b.Db.GetHourlyUsageForGuild(i.GuildID, "", userID, "", streakDays+1)
+	if err != nil {
+		slog.Error("Error getting hourly usage", "err", err)
+	}
+
+	earned := make(map[string]db.Achievement)
+	lines := []string{}
+	for _, achievement := range awarded {
+		earned[achievement.Key] = achievement
+		lines = append(lines, fmt.Sprintf(":trophy: **%s** %s", achievementLabel(achievement.Key), discordTimestamp(achievement.AwardedAt, "D")))
+	}
+
+	// Progress towards the ones a user can still work on
+	progress := map[string]string{
+		achievementCentury:   fmt.Sprintf("%d/%d reactions", min(activity.Total, centuryReactions), centuryReactions),
+		achievementStreak:    fmt.Sprintf("%d/%d days", min(reactionStreak(hourly, time.Now(), guildLocation(i.GuildID)), streakDays), streakDays),
+		achievementCollector: fmt.Sprintf("%d/%d emojis", min(activity.Distinct, collectorEmojis), collectorEmojis),
+	}
+	for _, rule := range achievementRules {
+		if _, ok := earned[rule.Key]; ok || progress[rule.Key] == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(":lock: **%s** %s (%s)", rule.Name, rule.Description, progress[rule.Key]))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Achievements",
+		Description: fmt.Sprintf("<@%s>\n%s", userID, strings.Join(lines, "\n")),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d earned", len(awarded))},
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// handleAchievementChannel - Set or clear the channel achievements are announced in
+func handleAchievementChannel(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	channelID := ""
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		channelID = options[0].ChannelValue(nil).ID
+	}
+
+	err := b.Db.SetGuildAchievementChannel(i.GuildID, channelID)
+	if err != nil {
+		return fmt.Errorf("setting achievement channel: %w", err)
+	}
+
+	msg := ":white_check_mark: Achievements will no longer be announced"
+	if channelID != "" {
+		msg = fmt.Sprintf(":white_check_mark: Achievements will be announced in <#%s>", channelID)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
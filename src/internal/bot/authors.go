@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxAuthorCacheSize - Message authors to remember before starting over
+const maxAuthorCacheSize = 10000
+
+// messageReactionAdd - discordgo's MessageReactionAdd plus message_author_id, which it doesn't decode yet
+type messageReactionAdd struct {
+	*discordgo.MessageReactionAdd
+	MessageAuthorID string `json:"message_author_id"`
+}
+
+type AuthorCache struct {
+	// authors map[MessageID]AuthorID
+	authors map[string]string
+	mutex   sync.RWMutex
+}
+
+var authors = &AuthorCache{authors: make(map[string]string)}
+
+// get - Cached author for a message
+func (a *AuthorCache) get(messageID string) (string, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	authorID, ok := a.authors[messageID]
+	return authorID, ok
+}
+
+// set - Remember the author of a message
+func (a *AuthorCache) set(messageID string, authorID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.authors) >= maxAuthorCacheSize {
+		a.authors = make(map[string]string)
+	}
+	a.authors[messageID] = authorID
+}
+
+// messageAuthor - Author of a reacted message, from the gateway event if present, otherwise cached REST lookups
+func messageAuthor(reaction *messageReactionAdd) string {
+	if reaction.MessageAuthorID != "" {
+		authors.set(reaction.MessageID, reaction.MessageAuthorID)
+		return reaction.MessageAuthorID
+	}
+
+	if authorID, ok := authors.get(reaction.MessageID); ok {
+		return authorID
+	}
+
+	message, err := b.DiscordSession.State.Message(reaction.ChannelID, reaction.MessageID)
+	if err != nil {
+		message, err = b.DiscordSession.ChannelMessage(reaction.ChannelID, reaction.MessageID)
+		if err != nil {
+			slog.Error("Failed to look up message author", "err", err, "channel_id", reaction.ChannelID, "message_id", reaction.MessageID)
+			return ""
+		}
+	}
+
+	authorID := ""
+	if message.Author != nil {
+		authorID = message.Author.ID
+	}
+	authors.set(reaction.MessageID, authorID)
+
+	return authorID
+}
+
+// HandleRawEvent - Decode reaction adds ourselves so message_author_id isn't lost
+func (bot *Bot) HandleRawEvent(discord *discordgo.Session, event *discordgo.Event) {
+	if event.Type != "MESSAGE_REACTION_ADD" {
+		return
+	}
+
+	reaction := &messageReactionAdd{}
+	err := json.Unmarshal(event.RawData, reaction)
+	if err != nil || reaction.MessageReactionAdd == nil || reaction.MessageReaction == nil {
+		slog.Error("Failed to decode reaction add", "err", err)
+		return
+	}
+
+	bot.HandleAddReaction(discord, reaction)
+}
@@ -4,16 +4,41 @@ import (
 	"fmt"
 	"log/slog"
 	"sort"
-	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
 )
 
 var (
-	integerOptionMinValue = 1.0
+	integerOptionMinValue   = 1.0
+	integerOptionZeroValue  = 0.0
+	tribesOptionMinValue    = 2.0
+	sameEmojiOptionMinValue = 2.0
+
+	periodDayChoices = []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "7 days", Value: 7},
+		{Name: "30 days", Value: 30},
+		{Name: "90 days", Value: 90},
+		{Name: "365 days", Value: 365},
+	}
 
 	defaultRunCommandPermissions int64 = discordgo.PermissionKickMembers
 
+	// ownerCommandPermissions - Hides owner commands from non admins, the handler still checks for the owner
+	ownerCommandPermissions int64 = discordgo.PermissionAdministrator
+	globalStatsDMPermission       = true
+
+	// textChannelTypes - Channels that hold reactions themselves, forum posts are picked as threads
+	textChannelTypes = []discordgo.ChannelType{
+		discordgo.ChannelTypeGuildText,
+		discordgo.ChannelTypeGuildNews,
+		discordgo.ChannelTypeGuildVoice,
+		discordgo.ChannelTypeGuildPublicThread,
+		discordgo.ChannelTypeGuildPrivateThread,
+		discordgo.ChannelTypeGuildNewsThread,
+	}
+
 	commands = []*discordgo.ApplicationCommand{
 		{
 			Name:                     "show-top-emojis",
@@ -23,11 +48,18 @@ var (
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "amount",
-					Description: "Amount to show",
+					Description: "Amount to show per page",
 					MinValue:    &integerOptionMinValue,
 					MaxValue:    20,
 					Required:    false,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only count reactions in this channel",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
 			},
 		},
 		{
@@ -35,16 +67,647 @@ var (
 			Description:              "Show top users",
 			DefaultMemberPermissions: &defaultRunCommandPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Amount to show per page",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    20,
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only count reactions in this channel",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+			},
+		},
+		{
+			Name:                     "show-top-receivers",
+			Description:              "Show users whose messages receive the most reactions",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Amount to show per page",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    20,
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only count reactions in this channel",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+			},
+		},
+		{
+			Name:                     "show-top-channels",
+			Description:              "Show top channels",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Amount to show",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    20,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-stats",
+			Description:              "Show stats for a single emoji",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Emoji to look up",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:                     "user-stats",
+			Description:              "Show emoji stats for a user",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Select user",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "compare-users",
+			Description:              "Compare two members' emoji habits head to head",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "a",
+					Description: "First member",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "b",
+					Description: "Second member",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "Emoji stats",
+			Type:                     discordgo.UserApplicationCommand,
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+		},
+		{
+			Name:                     "Emoji profile",
+			Type:                     discordgo.UserApplicationCommand,
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+		},
+		{
+			Name:                     "Reaction breakdown",
+			Type:                     discordgo.MessageApplicationCommand,
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+		},
+		{
+			Name:                     "unused-emojis",
+			Description:              "Show custom emojis with few or no uses",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to check (default 30 days)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "max-uses",
+					Description: "Include emojis with up to this many uses (default 0)",
+					MinValue:    &integerOptionZeroValue,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-trends",
+			Description:              "Show rising and falling emojis",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to compare against the one before it (default 7 days)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Amount to show",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    20,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "min-uses",
+					Description: "Ignore emojis with fewer uses across both periods (default 5)",
+					MinValue:    &integerOptionMinValue,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-pairs",
+			Description:              "Show emojis that get used together on the same messages",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Show what this emoji is often used with",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to check (default all time)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "score",
+					Description: "How to rank pairs (default Jaccard)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Jaccard (share of messages with either that have both)", Value: pairsScoreJaccard},
+						{Name: "Lift (how much more often than chance)", Value: pairsScoreLift},
+						{Name: "Messages together", Value: pairsScoreCount},
+					},
+					Required: false,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "amount",
 					Description: "Amount to show",
 					MinValue:    &integerOptionMinValue,
+					MaxValue:    25,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-twins",
+			Description:              "Find members with similar emoji habits, or group everyone into emoji tribes",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Member to find twins for (default you)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "tribes",
+					Description: "Group the whole guild into this many tribes instead",
+					MinValue:    &tribesOptionMinValue,
+					MaxValue:    10,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to compare (default all time)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-chart",
+			Description:              "Chart emoji usage over time",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "emojis",
+					Description: "Up to 5 emojis separated by spaces (default top 5)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Chart a user's activity instead",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "interval",
+					Description: "Bucket size (default day)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Daily", Value: "day"},
+						{Name: "Weekly", Value: "week"},
+					},
+					Required: false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "periods",
+					Description: "Number of days or weeks to show",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    60,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "style",
+					Description: "Chart style (default line)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Line", Value: "line"},
+						{Name: "Bar", Value: "bar"},
+					},
+					Required: false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-heatmap",
+			Description:              "Show reactions by weekday and hour in the server's timezone",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Only count this user's reactions",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Only count this emoji",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only count reactions in this channel",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to check (default 90 days)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "Image or emoji blocks (default image)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Image", Value: "image"},
+						{Name: "Text", Value: "text"},
+					},
+					Required: false,
+				},
+			},
+		},
+		{
+			Name:                     "channel-mood",
+			Description:              "Show the mix of positive, negative and neutral reactions in a channel over time",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to check (default this one)",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "interval",
+					Description: "Bucket size (default day)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Daily", Value: "day"},
+						{Name: "Weekly", Value: "week"},
+					},
+					Required: false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "periods",
+					Description: "Number of days or weeks to show",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    20,
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "emoji-sentiment",
+			Description:              "Show or override whether an emoji counts as positive, negative or neutral",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Emoji to check or change",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "sentiment",
+					Description: "New sentiment for this server, leave empty to show the current one",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Positive", Value: "positive"},
+						{Name: "Negative", Value: "negative"},
+						{Name: "Neutral", Value: "neutral"},
+						{Name: "Default", Value: "default"},
+					},
+					Required: false,
+				},
+			},
+		},
+		{
+			Name:                     "timezone",
+			Description:              "Show or set the server's timezone, used for heatmaps",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "timezone",
+					Description: "IANA timezone, e.g. Pacific/Auckland",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "achievements",
+			Description:              "Show the emoji achievements a member has earned",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Member to show (default you)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     "achievement-channel",
+			Description:              "Announce new achievements in a channel",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Channel to announce in, leave empty to stop announcing",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+			},
+		},
+		{
+			Name:                     "digest",
+			Description:              "Configure the scheduled emoji digest",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Post a digest to a channel on a schedule",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel to post in",
+							ChannelTypes: textChannelTypes,
+							Required:     true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "schedule",
+							Description: "Weekly (Mondays) or monthly (the 1st)",
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Weekly", Value: digestWeekly},
+								{Name: "Monthly", Value: digestMonthly},
+							},
+							Required: true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "time",
+							Description: "Time to post in UTC as HH:MM (default 09:00)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "disable",
+					Description: "Stop posting the digest",
+				},
+			},
+		},
+		{
+			Name:                     "starboard",
+			Description:              "Configure the starboard",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Repost messages that reach a number of reactions to a channel",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel to post in",
+							ChannelTypes: textChannelTypes,
+							Required:     true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "emoji",
+							Description: "Emoji to count (default ⭐)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "threshold",
+							Description: "Reactions needed to be posted (default 3)",
+							MinValue:    &integerOptionMinValue,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "remove-below",
+							Description: "Remove posts that drop back below the threshold",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "ignore",
+					Description: "Toggle whether messages in a channel can be starred",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel to ignore or stop ignoring",
+							ChannelTypes: textChannelTypes,
+							Required:     true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "disable",
+					Description: "Stop posting to the starboard",
+				},
+			},
+		},
+		{
+			Name:                     "spam-guard",
+			Description:              "Automatically scrub users who spam reactions",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Turn the spam guard on and change its thresholds",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "mod-log",
+							Description:  "Channel to send alerts with evidence to",
+							ChannelTypes: textChannelTypes,
+							Required:     false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "max-reactions",
+							Description: "Reactions allowed inside the window (default 15)",
+							MinValue:    &integerOptionMinValue,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "window-seconds",
+							Description: "Length of the sliding window (default 10)",
+							MinValue:    &integerOptionMinValue,
+							MaxValue:    3600,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "same-emoji-messages",
+							Description: "Messages the same emoji can go on inside the window (default 6)",
+							MinValue:    &sameEmojiOptionMinValue,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "scrub-minutes",
+							Description: "How long offenders are scrubbed for (default 30)",
+							MinValue:    &integerOptionMinValue,
+							MaxValue:    10080,
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "clear-mod-log",
+							Description: "Stop sending alerts to the mod-log channel",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "disable",
+					Description: "Turn the spam guard off",
+				},
+			},
+		},
+		{
+			Name:                     "global-stats",
+			Description:              "Bot owner only: emoji stats across every server",
+			DefaultMemberPermissions: &ownerCommandPermissions,
+			DMPermission:             &globalStatsDMPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Period to check (default all time)",
+					Choices:     periodDayChoices,
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "amount",
+					Description: "Rows to show per section",
+					MinValue:    &integerOptionMinValue,
 					MaxValue:    20,
 					Required:    false,
 				},
 			},
 		},
+		{
+			Name:                     "list-magic-tools",
+			Description:              "Lists the scripts running on reaction for users",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+		},
+		{
+			Name:                     "scrub-recent",
+			Description:              "Remove a user's recent reactions",
+			DefaultMemberPermissions: &defaultRunCommandPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Select user",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "hours",
+					Description: "How far back to go",
+					MinValue:    &integerOptionMinValue,
+					MaxValue:    720,
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Only remove this emoji (default every emoji)",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
 		{
 			Name:                     "add-magic-tool",
 			Description:              "Runs a script on reaction for user",
@@ -56,6 +719,33 @@ var (
 					Description: "Select user",
 					Required:    true,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Only remove this emoji (default every emoji)",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only in this channel and its threads (default every channel)",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "duration",
+					Description: "How long to run it for, e.g. 30m, 1h, 7d or 2w, up to a year (default until removed)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "Why, shown in /list-magic-tools",
+					MaxLength:   200,
+					Required:    false,
+				},
 			},
 		},
 		{
@@ -69,15 +759,70 @@ var (
 					Description: "Select user",
 					Required:    true,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "emoji",
+					Description:  "Only stop the rule for this emoji",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "Only stop the rule for this channel",
+					ChannelTypes: textChannelTypes,
+					Required:     false,
+				},
 			},
 		},
 	}
 
-	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"show-top-emojis":   showTopEmojis,
-		"show-top-users":    showTopUsers,
-		"add-magic-tool":    addAutoScrubber,
-		"remove-magic-tool": removeAutoScrubber,
+	commandHandlers = map[string]interactionHandler{
+		"show-top-emojis":     showTopEmojis,
+		"show-top-users":      showTopUsers,
+		"show-top-receivers":  showTopReceivers,
+		"show-top-channels":   showTopChannels,
+		"emoji-stats":         showEmojiStats,
+		"user-stats":          showUserStats,
+		"Emoji stats":         showUserStatsContext,
+		"Emoji profile":       showUserProfileContext,
+		"compare-users":       showCompareUsers,
+		"global-stats":        showGlobalStats,
+		"Reaction breakdown":  showReactionBreakdown,
+		"unused-emojis":       showUnusedEmojis,
+		"emoji-trends":        showEmojiTrends,
+		"emoji-chart":         showEmojiChart,
+		"emoji-pairs":         showEmojiPairs,
+		"emoji-twins":         showEmojiTwins,
+		"emoji-heatmap":       showEmojiHeatmap,
+		"channel-mood":        showChannelMood,
+		"emoji-sentiment":     handleEmojiSentiment,
+		"timezone":            handleTimezone,
+		"achievements":        showAchievements,
+		"achievement-channel": handleAchievementChannel,
+		"spam-guard":          handleSpamGuard,
+		"digest":              handleDigest,
+		"starboard":           handleStarboard,
+		"scrub-recent":        handleScrubRecent,
+		"add-magic-tool":      addAutoScrubber,
+		"list-magic-tools":    showListScrubs,
+		"remove-magic-tool":   removeAutoScrubber,
+	}
+
+	autocompleteHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"emoji-stats":       autocompleteEmoji,
+		"emoji-pairs":       autocompleteEmoji,
+		"emoji-heatmap":     autocompleteEmoji,
+		"emoji-sentiment":   autocompleteEmoji,
+		"scrub-recent":      autocompleteEmoji,
+		"add-magic-tool":    autocompleteEmoji,
+		"remove-magic-tool": autocompleteEmoji,
+	}
+
+	// componentHandlers - Keyed by the custom ID prefix before the first ":"
+	componentHandlers = map[string]interactionHandler{
+		leaderboardComponentPrefix: handleLeaderboardComponent,
+		scrubListComponentPrefix:   handleScrubListComponent,
 	}
 )
 
@@ -87,7 +832,7 @@ func (bot *Bot) RegisterCommands() {
 	for i, v := range commands {
 		cmd, err := bot.DiscordSession.ApplicationCommandCreate(bot.DiscordSession.State.User.ID, "", v)
 		if err != nil {
-			slog.Error("Error creating command", "err", err)
+			slog.Error("Error creating command", "err", err, "name", v.Name, "type", v.Type)
 		}
 
 		bot.registeredCommands[i] = cmd
@@ -105,7 +850,7 @@ func (bot *Bot) DeregisterCommands() {
 }
 
 // showTopEmojis - Show top emojis with users
-func showTopEmojis(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func showTopEmojis(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	// Access options in the order provided by the user.
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
@@ -113,61 +858,62 @@ func showTopEmojis(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		optionMap[opt.Name] = opt
 	}
 
-	amount := int64(5)
+	state := leaderboardState{Kind: leaderboardEmojis, PageSize: 5}
 	if opt, ok := optionMap["amount"]; ok {
-		amount = opt.IntValue()
+		state.PageSize = opt.IntValue()
 	}
 
-	top, err := b.Db.GetTopEmojisForGuild(i.GuildID, amount)
-	if err != nil {
-		slog.Error("Error getting top emojis", "err", err)
-		return
+	if opt, ok := optionMap["channel"]; ok {
+		state.ChannelID = opt.ChannelValue(nil).ID
 	}
 
-	// Sort keys
-	keys := make([]int, 0)
-	for k, _ := range top {
-		keys = append(keys, k)
+	return respondLeaderboard(s, i, state, discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+// showTopUsers - Show top users with emojis
+func showTopUsers(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
 	}
-	sort.Ints(keys)
-	msg := "Most used emojis:\n"
-	for _, v := range keys {
-		topUsers, err := b.Db.GetTopUsersForGuildEmoji(i.GuildID, top[v].EmojiID, 3)
-		if err != nil {
-			slog.Error("Error getting top users for guild emoji", "err", err)
-			continue
-		}
 
-		subkeys := make([]int, 0)
-		for k, _ := range topUsers {
-			subkeys = append(subkeys, k)
-		}
-		sort.Ints(subkeys)
+	state := leaderboardState{Kind: leaderboardUsers, PageSize: 5}
+	if opt, ok := optionMap["amount"]; ok {
+		state.PageSize = opt.IntValue()
+	}
 
-		users := []string{}
-		if top[v].EmojiID == top[v].EmojiName {
-			// Handle bad data with stock emojis
-			msg += fmt.Sprintf("%s %d", top[v].EmojiName, top[v].Count)
-		} else {
-			msg += fmt.Sprintf("<:%s:%s> %d", top[v].EmojiName, top[v].EmojiID, top[v].Count)
-		}
-		for _, sv := range subkeys {
-			users = append(users, fmt.Sprintf("<@%s>: %d", topUsers[sv].EmojiID, topUsers[sv].Count))
-		}
-		msg += "  (" + strings.Join(users, ", ") + ")\n"
+	if opt, ok := optionMap["channel"]; ok {
+		state.ChannelID = opt.ChannelValue(nil).ID
 	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content:         msg,
-			AllowedMentions: &discordgo.MessageAllowedMentions{},
-		},
-	})
+	return respondLeaderboard(s, i, state, discordgo.InteractionResponseChannelMessageWithSource)
 }
 
-// showTopUsers - Show top users with emojis
-func showTopUsers(s *discordgo.Session, i *discordgo.InteractionCreate) {
+// showTopReceivers - Show users whose messages receive the most reactions
+func showTopReceivers(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	state := leaderboardState{Kind: leaderboardReceivers, PageSize: 5}
+	if opt, ok := optionMap["amount"]; ok {
+		state.PageSize = opt.IntValue()
+	}
+
+	if opt, ok := optionMap["channel"]; ok {
+		state.ChannelID = opt.ChannelValue(nil).ID
+	}
+
+	return respondLeaderboard(s, i, state, discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+// showTopChannels - Show top channels with emojis
+func showTopChannels(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	// Access options in the order provided by the user.
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
@@ -180,10 +926,14 @@ func showTopUsers(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		amount = opt.IntValue()
 	}
 
-	top, err := b.Db.GetTopUsersForGuild(i.GuildID, amount)
+	top, err := b.Db.GetTopChannelsForGuild(i.GuildID, amount)
 	if err != nil {
-		slog.Error("Error getting top users", "err", err)
-		return
+		return fmt.Errorf("getting top channels: %w", err)
+	}
+
+	total, err := b.Db.CountUsageForGuild(i.GuildID, "", 0)
+	if err != nil {
+		return fmt.Errorf("counting guild usage: %w", err)
 	}
 
 	// Sort keys
@@ -193,44 +943,36 @@ func showTopUsers(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 	sort.Ints(keys)
 
-	msg := "Users who use the most emojis:\n"
+	view := leaderboardView{Title: "Channels with the most emojis", Footer: "All time", Total: total}
 	for _, v := range keys {
-		topUsers, err := b.Db.GetTopEmojisForGuildUser(i.GuildID, top[v].EmojiID, 3)
+		topEmojis, err := b.Db.GetTopEmojisForGuild(i.GuildID, top[v].EmojiID, 0, 0, 3)
 		if err != nil {
-			slog.Error("Error getting top emojis for guild user", "err", err)
+			slog.Error("Error getting top emojis for guild channel", "err", err)
 			continue
 		}
 
 		subkeys := make([]int, 0)
-		for k, _ := range topUsers {
+		for k := range topEmojis {
 			subkeys = append(subkeys, k)
 		}
 		sort.Ints(subkeys)
 
-		users := []string{}
-		msg += fmt.Sprintf("<@%s>: %d", top[v].EmojiID, top[v].Count)
+		row := leaderboardRow{Label: fmt.Sprintf("<#%s>", top[v].EmojiID), Count: top[v].Count}
 		for _, sv := range subkeys {
-			if topUsers[sv].EmojiID == topUsers[sv].EmojiName {
-				// Handle bad data with stock emojis
-				users = append(users, fmt.Sprintf("%s %d", topUsers[sv].EmojiName, topUsers[sv].Count))
-			} else {
-				users = append(users, fmt.Sprintf("<:%s:%s> %d", topUsers[sv].EmojiName, topUsers[sv].EmojiID, topUsers[sv].Count))
-			}
+			row.Details = append(row.Details, fmt.Sprintf("%s %d", formatEmoji(topEmojis[sv].EmojiName, topEmojis[sv].EmojiID), topEmojis[sv].Count))
 		}
-		msg += "  (" + strings.Join(users, ", ") + ")\n"
+		view.Rows = append(view.Rows, row)
 	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content:         msg,
-			AllowedMentions: &discordgo.MessageAllowedMentions{},
-		},
-	})
+	return respondLeaderboardView(s, i, view, nil, discordgo.InteractionResponseChannelMessageWithSource)
 }
 
 // addAutoScrubber - Scrubs emojis after a set period
-func addAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func addAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.GuildID == "" {
+		return respondGuildOnly(s, i)
+	}
+
 	// Access options in the order provided by the user.
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
@@ -243,41 +985,88 @@ func addAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		user = opt.UserValue(s)
 	} else {
 		slog.Error("Invalid user option provided")
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		return respond(s, i, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
 				Content:         "No user specified",
 				AllowedMentions: &discordgo.MessageAllowedMentions{},
 			},
 		})
-		return
 	}
 
-	err := scrub.startScrubbingUser(i.GuildID, user.ID)
+	expiresAt := time.Time{}
+	if opt, ok := optionMap["duration"]; ok {
+		duration, err := parseScrubDuration(opt.StringValue())
+		if err != nil {
+			return respond(s, i, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content:         "Duration must look like 30m, 1h, 7d or 2w, and be at most a year",
+					Flags:           discordgo.MessageFlagsEphemeral,
+					AllowedMentions: &discordgo.MessageAllowedMentions{},
+				},
+			})
+		}
+		expiresAt = time.Now().Add(duration)
+	}
+
+	rule := scrubRuleFromOptions(i.GuildID, user.ID, optionMap)
+	rule.AddedBy = interactionUserID(i)
+	rule.ExpiresAt = expiresAt
+	if opt, ok := optionMap["reason"]; ok {
+		rule.Reason = opt.StringValue()
+	}
+
+	err := scrub.startScrubbingUser(rule)
 	if err != nil {
-		slog.Error("Error starting auto scrubber", "err", err, "guild_id", i.GuildID, "user_id", user.ID)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content:         ":x:",
-				AllowedMentions: &discordgo.MessageAllowedMentions{},
-			},
-		})
+		return fmt.Errorf("starting auto scrubber for user %s: %w", user.ID, err)
+	}
 
-		return
+	content := fmt.Sprintf(":white_check_mark: Removing %s", describeScrubRule(rule))
+	if !expiresAt.IsZero() {
+		content += fmt.Sprintf(", expires %s", discordTimestamp(expiresAt, "R"))
 	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	return respond(s, i, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content:         ":white_check_mark:",
+			Content:         content,
 			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
 	})
 }
 
+// respondGuildOnly - Tell the user a command only works in a server
+func respondGuildOnly(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         "This command only works in a server",
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// scrubRuleFromOptions - Build the emoji and channel scope of a rule from command options
+func scrubRuleFromOptions(guildID string, userID string, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) db.Scrub {
+	rule := db.Scrub{GuildID: guildID, UserID: userID}
+	if opt, ok := optionMap["emoji"]; ok {
+		rule.Emoji = parseEmojiKey(guildID, opt.StringValue())
+	}
+	if opt, ok := optionMap["channel"]; ok {
+		rule.ChannelID = opt.ChannelValue(nil).ID
+	}
+
+	return rule
+}
+
 // removeAutoScrubber - Stops scrubbing emojis
-func removeAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func removeAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.GuildID == "" {
+		return respondGuildOnly(s, i)
+	}
+
 	// Access options in the order provided by the user.
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
@@ -290,33 +1079,48 @@ func removeAutoScrubber(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		user = opt.UserValue(s)
 	} else {
 		slog.Error("Invalid user option provided")
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		return respond(s, i, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
 				Content:         "No user specified",
 				AllowedMentions: &discordgo.MessageAllowedMentions{},
 			},
 		})
-		return
 	}
 
-	err := scrub.stopScrubbingUser(i.GuildID, user.ID)
-	if err != nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	// Without an emoji or channel every rule for the user goes
+	_, hasEmoji := optionMap["emoji"]
+	_, hasChannel := optionMap["channel"]
+	if !hasEmoji && !hasChannel {
+		err := scrub.stopScrubbingUser(i.GuildID, user.ID)
+		if err != nil {
+			return fmt.Errorf("stopping auto scrubber for user %s: %w", user.ID, err)
+		}
+
+		return respond(s, i, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content:         ":x:",
+				Content:         ":white_check_mark:",
 				AllowedMentions: &discordgo.MessageAllowedMentions{},
 			},
 		})
+	}
+
+	rule := scrubRuleFromOptions(i.GuildID, user.ID, optionMap)
+	removed, err := scrub.stopScrubbingRule(i.GuildID, user.ID, rule.Emoji, rule.ChannelID)
+	if err != nil {
+		return fmt.Errorf("stopping auto scrubber rule for user %s: %w", user.ID, err)
+	}
 
-		return
+	content := fmt.Sprintf(":white_check_mark: Stopped removing %s", describeScrubRule(rule))
+	if !removed {
+		content = fmt.Sprintf("No rule removing %s for that user", describeScrubRule(rule))
 	}
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	return respond(s, i, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content:         ":white_check_mark:",
+			Content:         content,
 			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
 	})
@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/chart"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// compareMaxEmojis - Emojis fetched per user, enough to tell "never" from "rarely"
+	compareMaxEmojis = 1000
+
+	// compareWeeks - Weeks of activity shown
+	compareWeeks = 12
+)
+
+// compareEmojis - One user's emoji counts, most used first
+type compareEmojis struct {
+	Ordered []db.EmojiMap
+	Counts  map[string]db.EmojiMap
+	Total   int64
+}
+
+// getCompareEmojis - Every emoji a user has used, via GetTopEmojisForGuildUser
+func getCompareEmojis(guildID string, userID string) (compareEmojis, error) {
+	emojis := compareEmojis{Counts: make(map[string]db.EmojiMap)}
+	top, err := b.Db.GetTopEmojisForGuildUser(guildID, "", 0, userID, compareMaxEmojis)
+	if err != nil {
+		return emojis, err
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range top {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, v := range keys {
+		emojis.Ordered = append(emojis.Ordered, top[v])
+		emojis.Counts[top[v].EmojiID] = top[v]
+		emojis.Total += top[v].Count
+	}
+
+	return emojis, nil
+}
+
+// exclusiveEmojis - Emojis in a that never show up in b, most used first
+func exclusiveEmojis(a compareEmojis, other compareEmojis, num int) []string {
+	emojis := []string{}
+	for _, emoji := range a.Ordered {
+		if len(emojis) >= num {
+			break
+		}
+		if _, ok := other.Counts[emoji.EmojiID]; !ok {
+			emojis = append(emojis, fmt.Sprintf("%s %d", formatEmoji(emoji.EmojiName, emoji.EmojiID), emoji.Count))
+		}
+	}
+
+	return emojis
+}
+
+// sharedEmojis - Emojis both use, ranked by the smaller share of each user's reactions
+func sharedEmojis(a compareEmojis, other compareEmojis, num int) []string {
+	type shared struct {
+		Emoji db.EmojiMap
+		Other int64
+		Score float64
+	}
+	both := []shared{}
+	for _, emoji := range a.Ordered {
+		if match, ok := other.Counts[emoji.EmojiID]; ok {
+			both = append(both, shared{
+				Emoji: emoji,
+				Other: match.Count,
+				Score: min(float64(emoji.Count)/float64(a.Total), float64(match.Count)/float64(other.Total)),
+			})
+		}
+	}
+	sort.SliceStable(both, func(x, y int) bool { return both[x].Score > both[y].Score })
+
+	emojis := []string{}
+	for idx, emoji := range both {
+		if idx >= num {
+			break
+		}
+		emojis = append(emojis, fmt.Sprintf("%s %d / %d", formatEmoji(emoji.Emoji.EmojiName, emoji.Emoji.EmojiID), emoji.Emoji.Count, emoji.Other))
+	}
+
+	return emojis
+}
+
+// topEmojiLines - First few rows of a user's emojis
+func topEmojiLines(emojis compareEmojis, num int) []string {
+	lines := []string{}
+	for idx, emoji := range emojis.Ordered {
+		if idx >= num {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s %d", formatEmoji(emoji.EmojiName, emoji.EmojiID), emoji.Count))
+	}
+
+	return lines
+}
+
+// showCompareUsers - Head to head comparison of two members
+func showCompareUsers(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	userA, userB := "", ""
+	if opt, ok := optionMap["a"]; ok {
+		userA = opt.UserValue(nil).ID
+	}
+	if opt, ok := optionMap["b"]; ok {
+		userB = opt.UserValue(nil).ID
+	}
+
+	emojisA, err := getCompareEmojis(i.GuildID, userA)
+	if err != nil {
+		return fmt.Errorf("getting emojis for user a: %w", err)
+	}
+
+	emojisB, err := getCompareEmojis(i.GuildID, userB)
+	if err != nil {
+		return fmt.Errorf("getting emojis for user b: %w", err)
+	}
+
+	timelineA, err := b.Db.GetUserTimelineForGuild(i.GuildID, userA, 7, compareWeeks)
+	if err != nil {
+		return fmt.Errorf("getting timeline for user a: %w", err)
+	}
+
+	timelineB, err := b.Db.GetUserTimelineForGuild(i.GuildID, userB, 7, compareWeeks)
+	if err != nil {
+		return fmt.Errorf("getting timeline for user b: %w", err)
+	}
+
+	similarity := newEmojiVector(emojisA.Counts).cosine(newEmojiVector(emojisB.Counts))
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Head to head",
+		Description: fmt.Sprintf("%s <@%s> vs %s <@%s>\n%.0f%% similar emoji habits", chart.PaletteLegend[0], userA, chart.PaletteLegend[1], userB, similarity*100),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Totals", Value: fmt.Sprintf("%s %d reactions, %d emojis\n%s %d reactions, %d emojis", chart.PaletteLegend[0], emojisA.Total, len(emojisA.Ordered), chart.PaletteLegend[1], emojisB.Total, len(emojisB.Ordered))},
+			{Name: fmt.Sprintf("%s Top emojis", chart.PaletteLegend[0]), Value: valueOrNone(topEmojiLines(emojisA, 5), "\n"), Inline: true},
+			{Name: fmt.Sprintf("%s Top emojis", chart.PaletteLegend[1]), Value: valueOrNone(topEmojiLines(emojisB, 5), "\n"), Inline: true},
+			{Name: "Shared favourites", Value: valueOrNone(sharedEmojis(emojisA, emojisB, 5), "\n"), Inline: true},
+			{Name: fmt.Sprintf("Only %s uses", chart.PaletteLegend[0]), Value: valueOrNone(exclusiveEmojis(emojisA, emojisB, 5), "\n"), Inline: true},
+			{Name: fmt.Sprintf("Only %s uses", chart.PaletteLegend[1]), Value: valueOrNone(exclusiveEmojis(emojisB, emojisA, 5), "\n"), Inline: true},
+			{Name: fmt.Sprintf("Weekly activity (%d weeks)", compareWeeks), Value: fmt.Sprintf("%s %s\n%s %s", chart.PaletteLegend[0], sparkline(timelineA), chart.PaletteLegend[1], sparkline(timelineB))},
+		},
+	}
+
+	labels := make([]string, compareWeeks)
+	now := time.Now().UTC()
+	for idx := range labels {
+		labels[idx] = now.AddDate(0, 0, -(compareWeeks-idx)*7+1).Format("01-02")
+	}
+
+	var buf bytes.Buffer
+	err = chart.Render(&buf, labels, []chart.Series{
+		{Values: timelineA, Color: chart.Palette[0]},
+		{Values: timelineB, Color: chart.Palette[1]},
+	}, chart.StyleLine)
+	if err != nil {
+		return fmt.Errorf("rendering chart: %w", err)
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://compare-users.png"}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+			Files: []*discordgo.File{
+				{
+					Name:        "compare-users.png",
+					ContentType: "image/png",
+					Reader:      &buf,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,342 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	digestWeekly  = "weekly"
+	digestMonthly = "monthly"
+
+	// digestCheckInterval - How often the scheduler looks for due digests
+	digestCheckInterval = time.Minute
+
+	// digestMaxAttempts - Posting attempts per period before the digest is skipped until the next one
+	digestMaxAttempts = 6
+)
+
+// digestRetry - Failed attempts at posting a guild's digest for one period
+type digestRetry struct {
+	due      time.Time
+	attempts int
+	next     time.Time
+}
+
+// digestRetries - Only touched by the scheduler goroutine, keyed by guild ID
+var digestRetries = make(map[string]digestRetry)
+
+// digestDue - Most recent scheduled time at or before now, and the one before it.
+// Weekly digests go out on Mondays, monthly on the 1st, both at postTime UTC
+func digestDue(schedule string, postTime string, now time.Time) (time.Time, time.Time, error) {
+	clock, err := time.Parse("15:04", postTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now = now.UTC()
+	switch schedule {
+	case digestMonthly:
+		due := time.Date(now.Year(), now.Month(), 1, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		if due.After(now) {
+			due = due.AddDate(0, -1, 0)
+		}
+		return due, due.AddDate(0, -1, 0), nil
+	default:
+		due := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		due = due.AddDate(0, 0, -((int(due.Weekday()) + 6) % 7))
+		if due.After(now) {
+			due = due.AddDate(0, 0, -7)
+		}
+		return due, due.AddDate(0, 0, -7), nil
+	}
+}
+
+// runDigestScheduler - Post due digests forever. Each period is claimed in the DB before
+// posting so restarts and overlapping checks never double post, and unclaimed if posting fails
+// so it is retried with backoff, until it fails permanently or runs out of attempts
+func runDigestScheduler() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		postDueDigests()
+	}
+}
+
+// postDueDigests - Check every configured digest once
+func postDueDigests() {
+	digests, err := b.Db.GetAllDigests()
+	if err != nil {
+		slog.Error("Failed to load digests", "err", err)
+		return
+	}
+
+	for _, digest := range digests {
+		due, previous, err := digestDue(digest.Schedule, digest.PostTime, time.Now())
+		if err != nil {
+			slog.Error("Invalid digest schedule", "err", err, "guild_id", digest.GuildID)
+			continue
+		}
+
+		if !digest.LastPostedAt.Before(due) {
+			continue
+		}
+
+		retry, ok := digestRetries[digest.GuildID]
+		if ok && retry.due.Equal(due) && time.Now().Before(retry.next) {
+			continue
+		}
+
+		claimed, err := b.Db.ClaimDigest(digest.GuildID, due)
+		if err != nil || !claimed {
+			if err != nil {
+				slog.Error("Failed to claim digest", "err", err, "guild_id", digest.GuildID)
+			}
+			continue
+		}
+
+		err = postDigest(digest, previous, due)
+		if err == nil {
+			delete(digestRetries, digest.GuildID)
+			continue
+		}
+
+		if !retry.due.Equal(due) {
+			retry = digestRetry{due: due}
+		}
+		retry.attempts++
+
+		// Missing access or a deleted channel won't fix itself, keep the claim and wait for the next period
+		var restErr *discordgo.RESTError
+		permanent := errors.As(err, &restErr) && restErr.Response != nil &&
+			(restErr.Response.StatusCode == http.StatusForbidden || restErr.Response.StatusCode == http.StatusNotFound)
+		if permanent || retry.attempts >= digestMaxAttempts {
+			slog.Error("Failed to post digest, skipping this period", "err", err, "guild_id", digest.GuildID, "channel_id", digest.ChannelID, "attempts", retry.attempts)
+			delete(digestRetries, digest.GuildID)
+			continue
+		}
+
+		// Give the claim back so a later check tries this period again, backing off each time
+		slog.Error("Failed to post digest", "err", err, "guild_id", digest.GuildID, "channel_id", digest.ChannelID, "attempts", retry.attempts)
+		retry.next = time.Now().Add(digestCheckInterval << retry.attempts)
+		digestRetries[digest.GuildID] = retry
+		err = b.Db.UnclaimDigest(digest.GuildID, due, digest.LastPostedAt)
+		if err != nil {
+			slog.Error("Failed to unclaim digest", "err", err, "guild_id", digest.GuildID)
+		}
+	}
+}
+
+// postDigest - Build and send one digest covering from (inclusive) to (exclusive)
+func postDigest(digest db.Digest, from time.Time, to time.Time) error {
+	embed, err := buildDigestEmbed(digest.GuildID, digest.Schedule, from, to)
+	if err != nil {
+		return fmt.Errorf("building digest: %w", err)
+	}
+
+	_, err = b.DiscordSession.ChannelMessageSendComplex(digest.ChannelID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+
+	return err
+}
+
+// buildDigestEmbed - Top emojis, top users, movers and custom emoji changes from (inclusive) to (exclusive)
+func buildDigestEmbed(guildID string, schedule string, from time.Time, to time.Time) (*discordgo.MessageEmbed, error) {
+	title := "Emoji week in review"
+	if schedule == digestMonthly {
+		title = "Emoji month in review"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:     title,
+		Timestamp: to.Format(time.RFC3339),
+	}
+
+	total, err := b.Db.CountUsageForGuildBetween(guildID, from, to)
+	if err != nil {
+		return embed, err
+	}
+	embed.Description = fmt.Sprintf("%d reactions from %s to %s", total, discordTimestamp(from, "f"), discordTimestamp(to, "f"))
+
+	topEmojis, err := b.Db.GetTopEmojisForGuildBetween(guildID, from, to, 5)
+	if err != nil {
+		return embed, err
+	}
+
+	topUsers, err := b.Db.GetTopUsersForGuildBetween(guildID, from, to, 5)
+	if err != nil {
+		return embed, err
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range topEmojis {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	emojis := []string{}
+	for _, v := range keys {
+		emojis = append(emojis, fmt.Sprintf("%s %s %d", rankLabel(int64(v)+1), formatEmoji(topEmojis[v].EmojiName, topEmojis[v].EmojiID), topEmojis[v].Count))
+	}
+
+	keys = make([]int, 0)
+	for k := range topUsers {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	users := []string{}
+	for _, v := range keys {
+		users = append(users, fmt.Sprintf("%s <@%s> %d", rankLabel(int64(v)+1), topUsers[v].EmojiID, topUsers[v].Count))
+	}
+
+	movers := []string{}
+	trends, err := getEmojiTrendsBetween(guildID, from, to, 5)
+	if err != nil {
+		slog.Error("Error getting emoji trends", "err", err)
+	}
+	for idx, trend := range trends {
+		if (idx < 3 && trend.Change() > 0) || (idx >= len(trends)-3 && trend.Change() < 0) {
+			movers = append(movers, trend.String())
+		}
+	}
+
+	added, unused, err := customEmojiChanges(guildID, from, to)
+	if err != nil {
+		slog.Error("Error getting custom emoji changes", "err", err)
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Top emojis", Value: valueOrNone(emojis, "\n"), Inline: true},
+		{Name: "Top users", Value: valueOrNone(users, "\n"), Inline: true},
+		{Name: "Biggest movers", Value: valueOrNone(movers, "\n")},
+		{Name: "New custom emojis", Value: truncate(valueOrNone(added, " "), embedFieldValueLimit)},
+		{Name: "Unused custom emojis", Value: truncate(valueOrNone(unused, " "), embedFieldValueLimit)},
+	}
+
+	return embed, nil
+}
+
+// customEmojiChanges - Custom emojis uploaded and custom emojis not used from (inclusive) to (exclusive)
+func customEmojiChanges(guildID string, from time.Time, to time.Time) ([]string, []string, error) {
+	added := []string{}
+	unused := []string{}
+
+	emojis, err := b.DiscordSession.GuildEmojis(guildID)
+	if err != nil {
+		return added, unused, err
+	}
+
+	activity, err := b.Db.GetEmojiTimelinesForGuildBetween(guildID, from, to, 1)
+	if err != nil {
+		return added, unused, err
+	}
+
+	for _, emoji := range emojis {
+		uploaded, err := discordgo.SnowflakeTimestamp(emoji.ID)
+		if err == nil && !uploaded.Before(from) && uploaded.Before(to) {
+			added = append(added, emoji.MessageFormat())
+		} else if err == nil && !uploaded.Before(to) {
+			// Uploaded after this period, neither new nor unused in it
+			continue
+		} else if _, ok := activity[emoji.ID]; !ok {
+			unused = append(unused, emoji.MessageFormat())
+		}
+	}
+
+	return added, unused, nil
+}
+
+// valueOrNone - Join values for an embed field, which can't be empty
+func valueOrNone(values []string, sep string) string {
+	if len(values) == 0 {
+		return "None"
+	}
+
+	return strings.Join(values, sep)
+}
+
+// handleDigest - /digest set and /digest disable
+func handleDigest(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return fmt.Errorf("no digest subcommand")
+	}
+
+	subcommand := data.Options[0]
+	if subcommand.Name == "disable" {
+		err := b.Db.RemoveDigest(i.GuildID)
+		if err != nil {
+			return fmt.Errorf("removing digest: %w", err)
+		}
+
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         ":white_check_mark:",
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	// Access options in the order provided by the user.
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, opt := range subcommand.Options {
+		optionMap[opt.Name] = opt
+	}
+
+	channelID := ""
+	if opt, ok := optionMap["channel"]; ok {
+		channelID = opt.ChannelValue(nil).ID
+	}
+
+	schedule := digestWeekly
+	if opt, ok := optionMap["schedule"]; ok {
+		schedule = opt.StringValue()
+	}
+
+	postTime := "09:00"
+	if opt, ok := optionMap["time"]; ok {
+		postTime = opt.StringValue()
+	}
+
+	// Start from the current period so the first digest goes out at the next scheduled time
+	due, _, err := digestDue(schedule, postTime, time.Now())
+	if err != nil {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "Time must be HH:MM in UTC, e.g. 09:00",
+				Flags:           discordgo.MessageFlagsEphemeral,
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	err = b.Db.SetDigest(i.GuildID, channelID, schedule, postTime, due)
+	if err != nil {
+		return fmt.Errorf("setting digest: %w", err)
+	}
+
+	label, next := "Weekly", due.AddDate(0, 0, 7)
+	if schedule == digestMonthly {
+		label, next = "Monthly", due.AddDate(0, 1, 0)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf(":white_check_mark: %s digest will be posted in <#%s>, next one %s", label, channelID, discordTimestamp(next, "F")),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
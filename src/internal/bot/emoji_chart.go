@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/chart"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+// emojiTokenRegex - Custom emojis may be typed without spaces between them
+var emojiTokenRegex = regexp.MustCompile(`<a?:\w+:\d+>|[^\s,<]+`)
+
+// maxChartSeries - One colour per series in chart.Palette
+const maxChartSeries = 5
+
+// showEmojiChart - Render usage over time as a PNG
+func showEmojiChart(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	bucketDays := 1
+	if opt, ok := optionMap["interval"]; ok && opt.StringValue() == "week" {
+		bucketDays = 7
+	}
+
+	buckets := 14
+	if bucketDays == 7 {
+		buckets = 12
+	}
+	if opt, ok := optionMap["periods"]; ok {
+		buckets = int(opt.IntValue())
+	}
+
+	style := chart.StyleLine
+	if opt, ok := optionMap["style"]; ok {
+		style = chart.Style(opt.StringValue())
+	}
+
+	series := []chart.Series{}
+	legend := []string{}
+	if opt, ok := optionMap["user"]; ok {
+		user := opt.UserValue(nil)
+		values, err := b.Db.GetUserTimelineForGuild(i.GuildID, user.ID, bucketDays, buckets)
+		if err != nil {
+			return fmt.Errorf("getting user timeline: %w", err)
+		}
+
+		series = append(series, chart.Series{Values: values, Color: chart.Palette[0]})
+		legend = append(legend, fmt.Sprintf("%s <@%s>", chart.PaletteLegend[0], user.ID))
+	} else {
+		timelines, err := b.Db.GetEmojiTimelinesForGuild(i.GuildID, bucketDays, buckets)
+		if err != nil {
+			return fmt.Errorf("getting emoji timelines: %w", err)
+		}
+
+		keys := []string{}
+		if opt, ok := optionMap["emojis"]; ok {
+			keys = parseEmojiKeys(i.GuildID, opt.StringValue())
+		} else {
+			keys = topTimelineKeys(timelines)
+		}
+
+		for _, key := range keys {
+			if len(series) >= maxChartSeries {
+				break
+			}
+
+			timeline, ok := timelines[key]
+			if !ok {
+				timeline.Counts = make([]int64, buckets)
+				timeline.EmojiName = key
+			}
+
+			idx := len(series)
+			series = append(series, chart.Series{Values: timeline.Counts, Color: chart.Palette[idx]})
+			legend = append(legend, fmt.Sprintf("%s %s", chart.PaletteLegend[idx], formatEmoji(timeline.EmojiName, timeline.EmojiID)))
+		}
+	}
+
+	// Label each bucket with the date it starts on
+	labels := make([]string, buckets)
+	now := time.Now().UTC()
+	for idx := range labels {
+		labels[idx] = now.AddDate(0, 0, -(buckets-idx)*bucketDays+1).Format("01-02")
+	}
+
+	var buf bytes.Buffer
+	err := chart.Render(&buf, labels, series, style)
+	if err != nil {
+		return fmt.Errorf("rendering chart: %w", err)
+	}
+
+	interval := "Daily"
+	if bucketDays == 7 {
+		interval = "Weekly"
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf("%s usage (UTC):\n%s", interval, strings.Join(legend, "  ")),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+			Files: []*discordgo.File{
+				{
+					Name:        "emoji-chart.png",
+					ContentType: "image/png",
+					Reader:      &buf,
+				},
+			},
+		},
+	})
+}
+
+// parseEmojiKeys - Split a list of emojis typed by the user into their keys
+func parseEmojiKeys(guildID string, value string) []string {
+	keys := []string{}
+	for _, token := range emojiTokenRegex.FindAllString(value, -1) {
+		keys = append(keys, parseEmojiKey(guildID, token))
+	}
+
+	return keys
+}
+
+// topTimelineKeys - Emoji keys ordered by total usage across the timeline
+func topTimelineKeys(timelines map[string]db.EmojiTimeline) []string {
+	totals := make(map[string]int64)
+	keys := make([]string, 0, len(timelines))
+	for key, timeline := range timelines {
+		for _, v := range timeline.Counts {
+			totals[key] += v
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(x, y int) bool { return totals[keys[x]] > totals[keys[y]] })
+
+	return keys
+}
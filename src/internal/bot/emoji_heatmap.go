@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/chart"
+)
+
+// heatmapDays - Rows of the heatmap, Monday first
+var heatmapDays = []string{"MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"}
+
+// heatmapBlocks - Text fallback scale, coldest first
+var heatmapBlocks = []string{"⬛", "🟦", "🟩", "🟨", "🟧", "🟥"}
+
+// buildHeatmap - Fold hourly UTC counts into weekday x hour cells in a timezone
+func buildHeatmap(hourly map[time.Time]int64, location *time.Location) [][]int64 {
+	cells := make([][]int64, len(heatmapDays))
+	for day := range cells {
+		cells[day] = make([]int64, 24)
+	}
+
+	for hour, count := range hourly {
+		local := hour.In(location)
+		day := (int(local.Weekday()) + 6) % 7
+		cells[day][local.Hour()] += count
+	}
+
+	return cells
+}
+
+// heatmapText - Emoji block rendering for when images aren't wanted
+func heatmapText(cells [][]int64) string {
+	max := int64(0)
+	for _, row := range cells {
+		for _, v := range row {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	lines := []string{"Columns are hours 00 to 23"}
+	for day, row := range cells {
+		line := fmt.Sprintf("`%s` ", heatmapDays[day])
+		for _, v := range row {
+			idx := 0
+			if max > 0 && v > 0 {
+				idx = 1 + int(v*int64(len(heatmapBlocks)-2)/max)
+			}
+			line += heatmapBlocks[idx]
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// heatmapPeak - Busiest weekday and hour
+func heatmapPeak(cells [][]int64) (int, int, int64) {
+	peakDay, peakHour, peak := 0, 0, int64(0)
+	for day, row := range cells {
+		for hour, v := range row {
+			if v > peak {
+				peakDay, peakHour, peak = day, hour, v
+			}
+		}
+	}
+
+	return peakDay, peakHour, peak
+}
+
+// showEmojiHeatmap - Reactions by weekday and hour in the guild's timezone
+func showEmojiHeatmap(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	scope := []string{}
+	userID := ""
+	if opt, ok := optionMap["user"]; ok {
+		userID = opt.UserValue(nil).ID
+		scope = append(scope, fmt.Sprintf("<@%s>", userID))
+	}
+
+	emojiID := ""
+	if opt, ok := optionMap["emoji"]; ok {
+		emojiID = parseEmojiKey(i.GuildID, opt.StringValue())
+		scope = append(scope, strings.TrimSpace(opt.StringValue()))
+	}
+
+	channelID := ""
+	if opt, ok := optionMap["channel"]; ok {
+		channelID = opt.ChannelValue(nil).ID
+		scope = append(scope, fmt.Sprintf("<#%s>", channelID))
+	}
+
+	days := int64(90)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	text := false
+	if opt, ok := optionMap["format"]; ok {
+		text = opt.StringValue() == "text"
+	}
+
+	hourly, err := b.Db.GetHourlyUsageForGuild(i.GuildID, channelID, userID, emojiID, days)
+	if err != nil {
+		return fmt.Errorf("getting hourly usage: %w", err)
+	}
+
+	location := guildLocation(i.GuildID)
+	cells := buildHeatmap(hourly, location)
+
+	msg := fmt.Sprintf("Reactions by weekday and hour, last %d days (%s)", days, location)
+	if len(scope) > 0 {
+		msg = fmt.Sprintf("Reactions by weekday and hour for %s, last %d days (%s)", strings.Join(scope, " in "), days, location)
+	}
+	if day, hour, peak := heatmapPeak(cells); peak > 0 {
+		msg += fmt.Sprintf("\nBusiest: %s %02d:00 with %d reactions", heatmapDays[day], hour, peak)
+	} else {
+		msg += "\nNo reactions recorded"
+	}
+
+	var buf bytes.Buffer
+	if !text {
+		err = chart.RenderHeatmap(&buf, heatmapDays, chart.HourLabels(), cells)
+		if err != nil {
+			slog.Error("Failed to render heatmap, falling back to text", "err", err)
+			text = true
+		}
+	}
+
+	if text {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         msg + "\n" + heatmapText(cells),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+			Files: []*discordgo.File{
+				{
+					Name:        "emoji-heatmap.png",
+					ContentType: "image/png",
+					Reader:      &buf,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,301 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	pairsScoreJaccard = "jaccard"
+	pairsScoreLift    = "lift"
+	pairsScoreCount   = "count"
+
+	// pairsMinTogether - Pairs seen on fewer messages are noise, and lift overrates them
+	pairsMinTogether = 3
+
+	// pairsPrecomputeInterval - How often big guilds get their pairs recomputed in the background
+	pairsPrecomputeInterval = time.Hour
+
+	// pairsPrecomputeMinUsage - Guilds with at least this many reactions are precomputed instead of queried on demand
+	pairsPrecomputeMinUsage = 50000
+
+	// pairsCacheTTL - How long an on demand result is reused
+	pairsCacheTTL = 10 * time.Minute
+)
+
+// pairsPeriods - Periods offered by /emoji-pairs, all precomputed for big guilds
+var pairsPeriods = []int64{0, 7, 30, 90, 365}
+
+type cachedCooccurrence struct {
+	data       db.EmojiCooccurrence
+	computedAt time.Time
+	expiresAt  time.Time
+}
+
+type PairsCache struct {
+	// entries map[GuildID:Days]cachedCooccurrence
+	entries map[string]cachedCooccurrence
+	mutex   sync.RWMutex
+}
+
+var pairs = &PairsCache{entries: make(map[string]cachedCooccurrence)}
+
+type emojiPairScore struct {
+	A        db.EmojiMap
+	B        db.EmojiMap
+	Together int64
+	// Jaccard - Messages with both over messages with either
+	Jaccard float64
+	// Lift - How much more often the pair appears than if the emojis were independent
+	Lift float64
+}
+
+// value - Score used for sorting
+func (p emojiPairScore) value(score string) float64 {
+	switch score {
+	case pairsScoreLift:
+		return p.Lift
+	case pairsScoreCount:
+		return float64(p.Together)
+	default:
+		return p.Jaccard
+	}
+}
+
+// get - Cached co-occurrence for a guild and period, if still fresh
+func (p *PairsCache) get(guildID string, days int64) (cachedCooccurrence, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	entry, ok := p.entries[fmt.Sprintf("%s:%d", guildID, days)]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// set - Cache co-occurrence for a guild and period
+func (p *PairsCache) set(guildID string, days int64, data db.EmojiCooccurrence, ttl time.Duration) cachedCooccurrence {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry := cachedCooccurrence{data: data, computedAt: time.Now(), expiresAt: time.Now().Add(ttl)}
+	p.entries[fmt.Sprintf("%s:%d", guildID, days)] = entry
+
+	return entry
+}
+
+// evictExpired - Drop stale entries, including guilds that are no longer precomputed
+func (p *PairsCache) evictExpired() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	for key, entry := range p.entries {
+		if now.After(entry.expiresAt) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// removeGuild - Drop every period for a guild the bot has left
+func (p *PairsCache) removeGuild(guildID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, days := range pairsPeriods {
+		delete(p.entries, fmt.Sprintf("%s:%d", guildID, days))
+	}
+}
+
+// runPairsPrecompute - Recompute pairs for big guilds forever, so the command rarely runs the self join for them.
+// Guilds aren't in state straight after connecting, so the first run waits an interval and is served on demand until then
+func runPairsPrecompute() {
+	ticker := time.NewTicker(pairsPrecomputeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		precomputePairs()
+	}
+}
+
+// precomputePairs - Refresh every period for guilds over pairsPrecomputeMinUsage
+func precomputePairs() {
+	pairs.evictExpired()
+
+	// Copy the IDs so gateway events can update state while the queries run
+	b.DiscordSession.State.RLock()
+	guildIDs := make([]string, 0, len(b.DiscordSession.State.Guilds))
+	for _, guild := range b.DiscordSession.State.Guilds {
+		guildIDs = append(guildIDs, guild.ID)
+	}
+	b.DiscordSession.State.RUnlock()
+
+	for _, guildID := range guildIDs {
+		total, err := b.Db.CountUsageForGuild(guildID, "", 0)
+		if err != nil {
+			slog.Error("Failed to count guild usage", "err", err, "guild_id", guildID)
+			continue
+		}
+		if total < pairsPrecomputeMinUsage {
+			continue
+		}
+
+		for _, days := range pairsPeriods {
+			data, err := b.Db.GetEmojiCooccurrenceForGuild(guildID, days, pairsMinTogether)
+			if err != nil {
+				slog.Error("Failed to precompute emoji pairs", "err", err, "guild_id", guildID, "days", days)
+				continue
+			}
+
+			// Outlive the interval so big guilds are always served from the cache
+			pairs.set(guildID, days, data, 2*pairsPrecomputeInterval)
+		}
+	}
+}
+
+// getEmojiCooccurrence - Cached co-occurrence, computing it on demand for small guilds
+func getEmojiCooccurrence(guildID string, days int64) (cachedCooccurrence, error) {
+	if entry, ok := pairs.get(guildID, days); ok {
+		return entry, nil
+	}
+
+	data, err := b.Db.GetEmojiCooccurrenceForGuild(guildID, days, pairsMinTogether)
+	if err != nil {
+		return cachedCooccurrence{}, err
+	}
+
+	return pairs.set(guildID, days, data, pairsCacheTTL), nil
+}
+
+// scoreEmojiPairs - Jaccard and lift for every pair, optionally only pairs including an emoji
+func scoreEmojiPairs(data db.EmojiCooccurrence, emojiID string, score string) []emojiPairScore {
+	scores := make([]emojiPairScore, 0, len(data.Pairs))
+	for _, pair := range data.Pairs {
+		first, second := data.Emojis[pair.A], data.Emojis[pair.B]
+		if emojiID != "" {
+			if pair.B == emojiID {
+				first, second = second, first
+			} else if pair.A != emojiID {
+				continue
+			}
+		}
+
+		scored := emojiPairScore{A: first, B: second, Together: pair.Count}
+		if union := first.Count + second.Count - pair.Count; union > 0 {
+			scored.Jaccard = float64(pair.Count) / float64(union)
+		}
+		if first.Count > 0 && second.Count > 0 {
+			scored.Lift = float64(pair.Count) * float64(data.Messages) / float64(first.Count*second.Count)
+		}
+		scores = append(scores, scored)
+	}
+
+	sort.Slice(scores, func(x, y int) bool {
+		if scores[x].value(score) == scores[y].value(score) {
+			return scores[x].Together > scores[y].Together
+		}
+		return scores[x].value(score) > scores[y].value(score)
+	})
+
+	return scores
+}
+
+// showEmojiPairs - Emojis used together on the same messages
+func showEmojiPairs(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	emojiID := ""
+	if opt, ok := optionMap["emoji"]; ok {
+		emojiID = parseEmojiKey(i.GuildID, opt.StringValue())
+	}
+
+	days := int64(0)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	score := pairsScoreJaccard
+	if opt, ok := optionMap["score"]; ok {
+		score = opt.StringValue()
+	}
+
+	amount := int64(10)
+	if opt, ok := optionMap["amount"]; ok {
+		amount = opt.IntValue()
+	}
+
+	cooccurrence, err := getEmojiCooccurrence(i.GuildID, days)
+	if err != nil {
+		return fmt.Errorf("getting emoji co-occurrence: %w", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Emojis used together",
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s · sorted by %s · pairs seen on %d+ messages", leaderboardPeriodLabel(days), score, pairsMinTogether),
+		},
+		Timestamp: cooccurrence.computedAt.Format(time.RFC3339),
+	}
+
+	if emojiID != "" {
+		emoji, ok := cooccurrence.data.Emojis[emojiID]
+		if !ok {
+			return respond(s, i, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content:         "No usage recorded for that emoji",
+					AllowedMentions: &discordgo.MessageAllowedMentions{},
+				},
+			})
+		}
+		embed.Title = fmt.Sprintf("Often used with %s", formatEmoji(emoji.EmojiName, emoji.EmojiID))
+	}
+
+	lines := []string{}
+	for idx, pair := range scoreEmojiPairs(cooccurrence.data, emojiID, score) {
+		if int64(idx) >= amount {
+			break
+		}
+
+		label := fmt.Sprintf("%s %s", formatEmoji(pair.A.EmojiName, pair.A.EmojiID), formatEmoji(pair.B.EmojiName, pair.B.EmojiID))
+		detail := fmt.Sprintf("%d messages", pair.Together)
+		if emojiID != "" {
+			// How often the partner shows up when the chosen emoji does
+			label = formatEmoji(pair.B.EmojiName, pair.B.EmojiID)
+			detail = fmt.Sprintf("%d messages (%.0f%%)", pair.Together, float64(pair.Together)/float64(pair.A.Count)*100)
+		}
+
+		line := fmt.Sprintf("%s %s — %s · Jaccard %.2f · lift %.1f×", rankLabel(int64(idx)+1), label, detail, pair.Jaccard, pair.Lift)
+		if len(strings.Join(append(lines, line), "\n")) > embedDescriptionLimit {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	embed.Description = "Not enough reactions yet to find emojis used together"
+	if len(lines) > 0 {
+		embed.Description = strings.Join(lines, "\n")
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
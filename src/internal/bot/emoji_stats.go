@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxAutocompleteChoices - Discord rejects autocomplete responses with more than 25 choices
+const maxAutocompleteChoices = 25
+
+// showEmojiStats - Show a detailed view for a single emoji
+func showEmojiStats(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	emojiID := ""
+	if opt, ok := optionMap["emoji"]; ok {
+		emojiID = parseEmojiKey(i.GuildID, opt.StringValue())
+	}
+
+	stats, err := b.Db.GetEmojiStatsForGuild(i.GuildID, emojiID)
+	if err != nil {
+		return fmt.Errorf("getting emoji stats: %w", err)
+	}
+
+	if stats.Count == 0 {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "No usage recorded for that emoji",
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	rank, err := b.Db.GetEmojiRankForGuild(i.GuildID, emojiID)
+	if err != nil {
+		slog.Error("Error getting emoji rank", "err", err)
+	}
+
+	weekly, err := b.Db.GetWeeklyUsageForGuildEmoji(i.GuildID, emojiID, 8)
+	if err != nil {
+		slog.Error("Error getting weekly emoji usage", "err", err)
+	}
+
+	topUsers, err := b.Db.GetTopUsersForGuildEmoji(i.GuildID, "", 0, emojiID, 5)
+	if err != nil {
+		slog.Error("Error getting top users for guild emoji", "err", err)
+	}
+
+	topChannels, err := b.Db.GetTopChannelsForGuildEmoji(i.GuildID, emojiID, 5)
+	if err != nil {
+		slog.Error("Error getting top channels for guild emoji", "err", err)
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range topUsers {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	users := []string{}
+	for _, v := range keys {
+		users = append(users, fmt.Sprintf("<@%s>: %d", topUsers[v].EmojiID, topUsers[v].Count))
+	}
+
+	keys = make([]int, 0)
+	for k := range topChannels {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	channels := []string{}
+	for _, v := range keys {
+		channels = append(channels, fmt.Sprintf("<#%s>: %d", topChannels[v].EmojiID, topChannels[v].Count))
+	}
+
+	trend := []string{}
+	for _, v := range weekly {
+		trend = append(trend, fmt.Sprintf("%d", v))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Stats for %s", formatEmoji(stats.EmojiName, stats.EmojiID)),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Total uses", Value: fmt.Sprintf("%d", stats.Count), Inline: true},
+			{Name: "Unique users", Value: fmt.Sprintf("%d", stats.Users), Inline: true},
+			{Name: "Rank", Value: fmt.Sprintf("#%d", rank), Inline: true},
+			{Name: "First seen", Value: discordTimestamp(stats.FirstSeen, "R"), Inline: true},
+			{Name: "Last seen", Value: discordTimestamp(stats.LastSeen, "R"), Inline: true},
+			{Name: "Weekly trend (8 weeks)", Value: sparkline(weekly) + "\n" + strings.Join(trend, " ")},
+			{Name: "Top users", Value: strings.Join(users, "\n"), Inline: true},
+			{Name: "Top channels", Value: strings.Join(channels, "\n"), Inline: true},
+		},
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// autocompleteEmoji - Suggest guild custom emojis and recently used emojis
+func autocompleteEmoji(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Focused {
+			query = strings.ToLower(strings.Trim(opt.StringValue(), ":"))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	seen := make(map[string]bool)
+	addChoice := func(emojiName string, emojiID string) {
+		key := emojiKey(emojiName, emojiID)
+		if seen[key] || len(choices) >= maxAutocompleteChoices {
+			return
+		}
+		if query != "" && !strings.Contains(strings.ToLower(emojiName), query) {
+			return
+		}
+
+		name := emojiName
+		if key != emojiName {
+			name = ":" + emojiName + ":"
+		}
+		seen[key] = true
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: key})
+	}
+
+	// Recently used first, then the rest of the guild catalog
+	recent, err := b.Db.GetRecentEmojisForGuild(i.GuildID, 50)
+	if err != nil {
+		slog.Error("Error getting recent emojis for guild", "err", err)
+	}
+	for _, emoji := range recent {
+		addChoice(emoji.EmojiName, emoji.EmojiID)
+	}
+
+	if guild, err := s.State.Guild(i.GuildID); err == nil {
+		for _, emoji := range guild.Emojis {
+			addChoice(emoji.Name, emoji.ID)
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		slog.Error("Error responding to emoji autocomplete", "err", err)
+	}
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+type emojiTrend struct {
+	EmojiID   string
+	EmojiName string
+	Previous  int64
+	Current   int64
+}
+
+// Change - Absolute change between periods
+func (t emojiTrend) Change() int64 {
+	return t.Current - t.Previous
+}
+
+// String - Render a trend row, e.g. "12 → 30 (+18, +150%)"
+func (t emojiTrend) String() string {
+	pct := "new"
+	if t.Previous > 0 {
+		pct = fmt.Sprintf("%+.0f%%", float64(t.Change())/float64(t.Previous)*100)
+	}
+
+	return fmt.Sprintf("%s %d → %d (%+d, %s)", formatEmoji(t.EmojiName, t.EmojiID), t.Previous, t.Current, t.Change(), pct)
+}
+
+// getEmojiTrends - Compare the last period against the one before it, dropping low volume emojis
+func getEmojiTrends(guildID string, days int, minUses int64) ([]emojiTrend, error) {
+	timelines, err := b.Db.GetEmojiTimelinesForGuild(guildID, days, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return emojiTrendsFromTimelines(timelines, minUses), nil
+}
+
+// getEmojiTrendsBetween - Compare from-to against the equally long period before it, dropping low volume emojis
+func getEmojiTrendsBetween(guildID string, from time.Time, to time.Time, minUses int64) ([]emojiTrend, error) {
+	timelines, err := b.Db.GetEmojiTimelinesForGuildBetween(guildID, from.Add(-to.Sub(from)), to, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return emojiTrendsFromTimelines(timelines, minUses), nil
+}
+
+// emojiTrendsFromTimelines - Trends from two bucket timelines, biggest risers first
+func emojiTrendsFromTimelines(timelines map[string]db.EmojiTimeline, minUses int64) []emojiTrend {
+	trends := make([]emojiTrend, 0, len(timelines))
+	for _, timeline := range timelines {
+		trend := emojiTrend{
+			EmojiID:   timeline.EmojiID,
+			EmojiName: timeline.EmojiName,
+			Previous:  timeline.Counts[0],
+			Current:   timeline.Counts[1],
+		}
+		if trend.Previous+trend.Current < minUses || trend.Change() == 0 {
+			continue
+		}
+
+		trends = append(trends, trend)
+	}
+
+	// Biggest risers first, biggest fallers last
+	sort.Slice(trends, func(x, y int) bool {
+		if trends[x].Change() == trends[y].Change() {
+			return trends[x].Current > trends[y].Current
+		}
+		return trends[x].Change() > trends[y].Change()
+	})
+
+	return trends
+}
+
+// showEmojiTrends - Show the biggest rising and falling emojis
+func showEmojiTrends(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	days := int64(7)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	amount := int64(5)
+	if opt, ok := optionMap["amount"]; ok {
+		amount = opt.IntValue()
+	}
+
+	minUses := int64(5)
+	if opt, ok := optionMap["min-uses"]; ok {
+		minUses = opt.IntValue()
+	}
+
+	trends, err := getEmojiTrends(i.GuildID, int(days), minUses)
+	if err != nil {
+		return fmt.Errorf("getting emoji trends: %w", err)
+	}
+
+	risers := []string{}
+	for _, trend := range trends {
+		if trend.Change() <= 0 || int64(len(risers)) >= amount {
+			break
+		}
+		risers = append(risers, trend.String())
+	}
+
+	fallers := []string{}
+	for idx := len(trends) - 1; idx >= 0; idx-- {
+		if trends[idx].Change() >= 0 || int64(len(fallers)) >= amount {
+			break
+		}
+		fallers = append(fallers, trends[idx].String())
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Emoji trends, last %d days vs the %d days before", days, days),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: ":chart_with_upwards_trend: Rising", Value: joinRows(risers, embedFieldValueLimit)},
+			{Name: ":chart_with_downwards_trend: Falling", Value: joinRows(fallers, embedFieldValueLimit)},
+		},
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
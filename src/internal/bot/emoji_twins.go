@@ -0,0 +1,343 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// twinsMinUses - Members with fewer reactions don't have a meaningful profile yet
+	twinsMinUses = 10
+
+	// twinsMaxUsers - Most active members compared, keeps clustering cheap in big guilds
+	twinsMaxUsers = 500
+
+	// tribesMaxIterations - k-means normally settles well before this
+	tribesMaxIterations = 20
+)
+
+// emojiVector - Unit length emoji distribution for one member
+type emojiVector map[string]float64
+
+// newEmojiVector - Normalise raw counts so heavy and light reactors compare on habits, not volume
+func newEmojiVector(counts map[string]db.EmojiMap) emojiVector {
+	norm := 0.0
+	for _, emoji := range counts {
+		norm += float64(emoji.Count * emoji.Count)
+	}
+	norm = math.Sqrt(norm)
+
+	vector := make(emojiVector, len(counts))
+	for key, emoji := range counts {
+		if norm > 0 {
+			vector[key] = float64(emoji.Count) / norm
+		}
+	}
+
+	return vector
+}
+
+// cosine - Cosine similarity, both vectors are unit length so this is the dot product
+func (v emojiVector) cosine(other emojiVector) float64 {
+	// Iterate the smaller map
+	if len(other) < len(v) {
+		v, other = other, v
+	}
+
+	dot := 0.0
+	for key, value := range v {
+		dot += value * other[key]
+	}
+
+	return dot
+}
+
+// top - Keys with the largest weights
+func (v emojiVector) top(num int) []string {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(x, y int) bool {
+		if v[keys[x]] == v[keys[y]] {
+			return keys[x] < keys[y]
+		}
+		return v[keys[x]] > v[keys[y]]
+	})
+
+	if len(keys) > num {
+		keys = keys[:num]
+	}
+
+	return keys
+}
+
+// emojiTribe - A cluster of members with similar habits
+type emojiTribe struct {
+	Centroid emojiVector
+	Members  []string
+}
+
+// findEmojiTribes - Spherical k-means over member vectors. Seeded with the most active member
+// then repeatedly the member least like any seed, so results are stable between runs
+func findEmojiTribes(vectors map[string]emojiVector, activity map[string]int64, k int) []emojiTribe {
+	users := make([]string, 0, len(vectors))
+	for userID := range vectors {
+		users = append(users, userID)
+	}
+	sort.Slice(users, func(x, y int) bool {
+		if activity[users[x]] == activity[users[y]] {
+			return users[x] < users[y]
+		}
+		return activity[users[x]] > activity[users[y]]
+	})
+
+	if k > len(users) {
+		k = len(users)
+	}
+	if k == 0 {
+		return nil
+	}
+
+	centroids := []emojiVector{vectors[users[0]]}
+	for len(centroids) < k {
+		farthest, farthestSimilarity := "", math.Inf(1)
+		for _, userID := range users {
+			best := math.Inf(-1)
+			for _, centroid := range centroids {
+				best = math.Max(best, vectors[userID].cosine(centroid))
+			}
+			if best < farthestSimilarity {
+				farthest, farthestSimilarity = userID, best
+			}
+		}
+		centroids = append(centroids, vectors[farthest])
+	}
+
+	assignments := make(map[string]int, len(users))
+	for iteration := 0; iteration < tribesMaxIterations; iteration++ {
+		changed := false
+		for _, userID := range users {
+			best, bestSimilarity := 0, math.Inf(-1)
+			for idx, centroid := range centroids {
+				if similarity := vectors[userID].cosine(centroid); similarity > bestSimilarity {
+					best, bestSimilarity = idx, similarity
+				}
+			}
+			if previous, ok := assignments[userID]; !ok || previous != best {
+				assignments[userID] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		// Mean of each tribe, renormalised
+		sums := make([]map[string]float64, k)
+		for idx := range sums {
+			sums[idx] = make(map[string]float64)
+		}
+		for _, userID := range users {
+			for key, value := range vectors[userID] {
+				sums[assignments[userID]][key] += value
+			}
+		}
+		for idx, sum := range sums {
+			if len(sum) == 0 {
+				continue
+			}
+			norm := 0.0
+			for _, value := range sum {
+				norm += value * value
+			}
+			norm = math.Sqrt(norm)
+			centroid := make(emojiVector, len(sum))
+			for key, value := range sum {
+				centroid[key] = value / norm
+			}
+			centroids[idx] = centroid
+		}
+	}
+
+	tribes := make([]emojiTribe, k)
+	for idx := range tribes {
+		tribes[idx].Centroid = centroids[idx]
+	}
+	// Users are sorted by activity so members end up most active first
+	for _, userID := range users {
+		tribes[assignments[userID]].Members = append(tribes[assignments[userID]].Members, userID)
+	}
+
+	// Biggest tribes first, dropping any that ended up empty
+	sort.SliceStable(tribes, func(x, y int) bool {
+		return len(tribes[x].Members) > len(tribes[y].Members)
+	})
+	for len(tribes) > 0 && len(tribes[len(tribes)-1].Members) == 0 {
+		tribes = tribes[:len(tribes)-1]
+	}
+
+	return tribes
+}
+
+// showEmojiTwins - Members with the most similar emoji habits, or the whole guild grouped into tribes
+func showEmojiTwins(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	userID := ""
+	if i.Member != nil && i.Member.User != nil {
+		userID = i.Member.User.ID
+	}
+	if opt, ok := optionMap["user"]; ok {
+		userID = opt.UserValue(nil).ID
+	}
+
+	days := int64(0)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	tribes := int64(0)
+	if opt, ok := optionMap["tribes"]; ok {
+		tribes = opt.IntValue()
+	}
+
+	counts, err := b.Db.GetEmojiVectorsForGuild(i.GuildID, days, twinsMinUses, twinsMaxUsers)
+	if err != nil {
+		return fmt.Errorf("getting emoji vectors: %w", err)
+	}
+
+	// Only the most active members are loaded, so fetch the user themselves if they missed the cut
+	if _, ok := counts[userID]; !ok && tribes == 0 && userID != "" {
+		emojis, err := b.Db.GetEmojiVectorForUser(i.GuildID, userID, days)
+		if err != nil {
+			return fmt.Errorf("getting emoji vector for user: %w", err)
+		}
+
+		total := int64(0)
+		for _, emoji := range emojis {
+			total += emoji.Count
+		}
+		if total >= twinsMinUses {
+			counts[userID] = emojis
+		}
+	}
+
+	names := make(map[string]string)
+	vectors := make(map[string]emojiVector, len(counts))
+	activity := make(map[string]int64, len(counts))
+	for id, emojis := range counts {
+		vectors[id] = newEmojiVector(emojis)
+		for key, emoji := range emojis {
+			names[key] = emoji.EmojiName
+			activity[id] += emoji.Count
+		}
+	}
+
+	var embed *discordgo.MessageEmbed
+	if tribes > 0 {
+		embed = buildEmojiTribesEmbed(findEmojiTribes(vectors, activity, int(tribes)), names)
+	} else {
+		embed = buildEmojiTwinsEmbed(userID, vectors, names)
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("%s · members with %d+ reactions", leaderboardPeriodLabel(days), twinsMinUses),
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// buildEmojiTwinsEmbed - The five members closest to a user
+func buildEmojiTwinsEmbed(userID string, vectors map[string]emojiVector, names map[string]string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{Title: "Emoji twins"}
+
+	vector, ok := vectors[userID]
+	if !ok {
+		embed.Description = fmt.Sprintf("<@%s> hasn't reacted enough to find their twins", userID)
+		return embed
+	}
+
+	type twin struct {
+		UserID     string
+		Similarity float64
+	}
+	twins := []twin{}
+	for otherID, other := range vectors {
+		if otherID != userID {
+			twins = append(twins, twin{otherID, vector.cosine(other)})
+		}
+	}
+	sort.Slice(twins, func(x, y int) bool {
+		if twins[x].Similarity == twins[y].Similarity {
+			return twins[x].UserID < twins[y].UserID
+		}
+		return twins[x].Similarity > twins[y].Similarity
+	})
+
+	lines := []string{fmt.Sprintf("Members who react most like <@%s> %s", userID, formatEmojiKeys(vector.top(3), names))}
+	for idx, twin := range twins {
+		if idx >= 5 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s <@%s> %.0f%% match %s", rankLabel(int64(idx)+1), twin.UserID, twin.Similarity*100, formatEmojiKeys(vectors[twin.UserID].top(3), names)))
+	}
+	if len(twins) == 0 {
+		lines = append(lines, "Nobody else has reacted enough to compare yet")
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	return embed
+}
+
+// buildEmojiTribesEmbed - One field per tribe with its signature emojis and most active members
+func buildEmojiTribesEmbed(tribes []emojiTribe, names map[string]string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{Title: "Emoji tribes"}
+	if len(tribes) == 0 {
+		embed.Description = "Not enough members have reacted to find tribes yet"
+		return embed
+	}
+
+	for idx, tribe := range tribes {
+		members := []string{}
+		for _, memberID := range tribe.Members {
+			if len(members) >= 5 {
+				members = append(members, fmt.Sprintf("and %d more", len(tribe.Members)-5))
+				break
+			}
+			members = append(members, fmt.Sprintf("<@%s>", memberID))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  truncate(fmt.Sprintf("Tribe %d · %d members", idx+1, len(tribe.Members)), embedFieldNameLimit),
+			Value: truncate(formatEmojiKeys(tribe.Centroid.top(3), names)+"\n"+strings.Join(members, ", "), embedFieldValueLimit),
+		})
+	}
+
+	return embed
+}
+
+// formatEmojiKeys - Render emoji keys in a row
+func formatEmojiKeys(keys []string, names map[string]string) string {
+	emojis := make([]string, 0, len(keys))
+	for _, key := range keys {
+		emojis = append(emojis, formatEmoji(names[key], key))
+	}
+
+	return strings.Join(emojis, " ")
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	customEmojiRegex = regexp.MustCompile(`^<a?:(\w+):(\d+)>$`)
+
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+)
+
+// formatEmoji - Render an emoji for a message, stock emojis have no ID (or their name as ID)
+func formatEmoji(emojiName string, emojiID string) string {
+	if emojiID == "" || emojiID == emojiName {
+		return emojiName
+	}
+
+	if emojiAnimated(emojiID) {
+		return fmt.Sprintf("<a:%s:%s>", emojiName, emojiID)
+	}
+
+	return fmt.Sprintf("<:%s:%s>", emojiName, emojiID)
+}
+
+// emojiAnimated - Whether a custom emoji in any guild the bot is in is animated, emoji IDs are global
+func emojiAnimated(emojiID string) bool {
+	state := b.DiscordSession.State
+	state.RLock()
+	defer state.RUnlock()
+
+	for _, guild := range state.Guilds {
+		for _, emoji := range guild.Emojis {
+			if emoji.ID == emojiID {
+				return emoji.Animated
+			}
+		}
+	}
+
+	return false
+}
+
+// emojiKey - Key used to group an emoji, stock emojis have no ID so use their name
+func emojiKey(emojiName string, emojiID string) string {
+	if emojiID == "" {
+		return emojiName
+	}
+
+	return emojiID
+}
+
+// formatEmojiKey - Render an emoji from its key, custom emoji names are looked up in the guild
+func formatEmojiKey(guildID string, key string) string {
+	if _, err := strconv.ParseUint(key, 10, 64); err != nil {
+		return key
+	}
+
+	if emoji, err := b.DiscordSession.State.Emoji(guildID, key); err == nil {
+		return formatEmoji(emoji.Name, emoji.ID)
+	}
+
+	// Discord only needs the ID to render it
+	return formatEmoji("emoji", key)
+}
+
+// parseEmojiKey - Turn user input into the key used to store an emoji (ID for custom, name for stock)
+func parseEmojiKey(guildID string, value string) string {
+	value = strings.TrimSpace(value)
+	if m := customEmojiRegex.FindStringSubmatch(value); m != nil {
+		return m[2]
+	}
+
+	// Allow :name: for custom emojis in this guild
+	name := strings.Trim(value, ":")
+	if guild, err := b.DiscordSession.State.Guild(guildID); err == nil {
+		for _, emoji := range guild.Emojis {
+			if emoji.Name == name {
+				return emoji.ID
+			}
+		}
+	}
+
+	return value
+}
+
+// sparkline - Render counts as a row of block characters
+func sparkline(values []int64) string {
+	max := int64(0)
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		idx := 0
+		if max > 0 {
+			idx = int(v * int64(len(sparkBlocks)-1) / max)
+		}
+		line[i] = sparkBlocks[idx]
+	}
+
+	return string(line)
+}
+
+// discordTimestamp - Render a time using Discord's localised timestamp markup
+func discordTimestamp(t time.Time, style string) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style)
+}
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type Owners struct {
+	ids   map[string]bool
+	mutex sync.Mutex
+}
+
+var owners = &Owners{}
+
+// isOwner - Whether a user owns the application, or is on the team that does. Loaded on first use
+func (o *Owners) isOwner(userID string) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.ids == nil {
+		app, err := b.DiscordSession.Application("@me")
+		if err != nil {
+			slog.Error("Failed to get application owner", "err", err)
+			return false
+		}
+
+		o.ids = make(map[string]bool)
+		if app.Owner != nil {
+			o.ids[app.Owner.ID] = true
+		}
+		if app.Team != nil {
+			o.ids[app.Team.OwnerID] = true
+			for _, member := range app.Team.Members {
+				if member.User != nil {
+					o.ids[member.User.ID] = true
+				}
+			}
+		}
+	}
+
+	return o.ids[userID]
+}
+
+// interactionUserID - Who ran an interaction, in a guild or a DM
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+
+	return ""
+}
+
+// guildName - Guild name from state, falling back to the ID
+func guildName(guildID string) string {
+	if guild, err := b.DiscordSession.State.Guild(guildID); err == nil && guild.Name != "" {
+		return guild.Name
+	}
+
+	return guildID
+}
+
+// showGlobalStats - Owner only report across every guild the bot is in
+func showGlobalStats(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	err := deferEphemeral(s, i)
+	if err != nil {
+		return fmt.Errorf("deferring global stats: %w", err)
+	}
+
+	if !owners.isOwner(interactionUserID(i)) {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "Only the bot's owner can see stats across servers",
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	days := int64(0)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	amount := int64(10)
+	if opt, ok := optionMap["amount"]; ok {
+		amount = opt.IntValue()
+	}
+
+	totals, err := b.Db.GetGlobalTotals(days)
+	if err != nil {
+		return fmt.Errorf("getting global totals: %w", err)
+	}
+
+	topEmojis, err := b.Db.GetTopEmojisGlobal(days, amount)
+	if err != nil {
+		return fmt.Errorf("getting global top emojis: %w", err)
+	}
+
+	guilds, err := b.Db.GetGuildActivityRanking(days, amount)
+	if err != nil {
+		return fmt.Errorf("getting guild activity ranking: %w", err)
+	}
+
+	spread, err := b.Db.GetEmojiSpread(amount)
+	if err != nil {
+		return fmt.Errorf("getting emoji spread: %w", err)
+	}
+
+	emojiLines := []string{}
+	for idx, emoji := range topEmojis {
+		emojiLines = append(emojiLines, fmt.Sprintf("%s %s %d in %d servers", rankLabel(int64(idx)+1), formatEmoji(emoji.EmojiName, emoji.EmojiID), emoji.Count, emoji.Guilds))
+	}
+
+	guildLines := []string{}
+	for idx, guild := range guilds {
+		guildLines = append(guildLines, fmt.Sprintf("%s %s: %d reactions from %d users", rankLabel(int64(idx)+1), guildName(guild.GuildID), guild.Reactions, guild.Users))
+	}
+
+	spreadLines := []string{}
+	for _, emoji := range spread {
+		spreadLines = append(spreadLines, fmt.Sprintf("%s from %s, now in %d servers with %d uses since %s", formatEmoji(emoji.EmojiName, emoji.EmojiID), guildName(emoji.OriginGuildID), emoji.Guilds, emoji.Count, discordTimestamp(emoji.FirstSeen, "d")))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Stats across every server",
+		Description: fmt.Sprintf("%d reactions from %d users with %d emojis in %d servers", totals.Reactions, totals.Users, totals.Emojis, totals.Guilds),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Top emojis", Value: truncate(valueOrNone(emojiLines, "\n"), embedFieldValueLimit)},
+			{Name: "Most active servers", Value: truncate(valueOrNone(guildLines, "\n"), embedFieldValueLimit)},
+			{Name: "Custom emojis that spread (all time)", Value: truncate(valueOrNone(spreadLines, "\n"), embedFieldValueLimit)},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: leaderboardPeriodLabel(days)},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
@@ -0,0 +1,346 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	leaderboardEmojis    = "emojis"
+	leaderboardUsers     = "users"
+	leaderboardReceivers = "receivers"
+
+	// leaderboardComponentPrefix - Custom ID prefix routed to handleLeaderboardComponent
+	leaderboardComponentPrefix = "lb"
+)
+
+var leaderboardPeriods = []struct {
+	Label string
+	Days  int64
+}{
+	{"All time", 0},
+	{"Last 7 days", 7},
+	{"Last 30 days", 30},
+	{"Last 90 days", 90},
+	{"Last 365 days", 365},
+}
+
+// leaderboardState - Everything needed to render a page, encoded into component custom IDs
+// so buttons keep working after a restart
+type leaderboardState struct {
+	Kind      string
+	ChannelID string
+	Days      int64
+	Page      int64
+	PageSize  int64
+}
+
+// customID - Encode state for a component, e.g. lb:next:emojis:123:7:0:5
+func (l leaderboardState) customID(action string) string {
+	return strings.Join([]string{
+		leaderboardComponentPrefix,
+		action,
+		l.Kind,
+		l.ChannelID,
+		strconv.FormatInt(l.Days, 10),
+		strconv.FormatInt(l.Page, 10),
+		strconv.FormatInt(l.PageSize, 10),
+	}, ":")
+}
+
+// parseLeaderboardCustomID - Decode a component custom ID into its action and state
+func parseLeaderboardCustomID(customID string) (string, leaderboardState, error) {
+	state := leaderboardState{}
+	parts := strings.Split(customID, ":")
+	if len(parts) != 7 || parts[0] != leaderboardComponentPrefix {
+		return "", state, fmt.Errorf("invalid leaderboard custom ID %q", customID)
+	}
+
+	var err error
+	state.Kind = parts[2]
+	state.ChannelID = parts[3]
+	if state.Days, err = strconv.ParseInt(parts[4], 10, 64); err != nil {
+		return "", state, err
+	}
+	if state.Page, err = strconv.ParseInt(parts[5], 10, 64); err != nil {
+		return "", state, err
+	}
+	if state.PageSize, err = strconv.ParseInt(parts[6], 10, 64); err != nil {
+		return "", state, err
+	}
+	if state.PageSize < 1 {
+		state.PageSize = 5
+	}
+
+	return parts[1], state, nil
+}
+
+// respondLeaderboard - Render a leaderboard page as a new message or in place of the clicked one
+func respondLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate, state leaderboardState, responseType discordgo.InteractionResponseType) error {
+	view, state, pages, err := renderLeaderboard(i.GuildID, state)
+	if err != nil {
+		return fmt.Errorf("rendering leaderboard: %w", err)
+	}
+
+	return respondLeaderboardView(s, i, view, leaderboardComponents(state, pages), responseType)
+}
+
+// handleLeaderboardComponent - Page buttons and period select
+func handleLeaderboardComponent(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.MessageComponentData()
+	action, state, err := parseLeaderboardCustomID(data.CustomID)
+	if err != nil {
+		return fmt.Errorf("parsing leaderboard component: %w", err)
+	}
+
+	switch action {
+	case "first":
+		state.Page = 0
+	case "prev":
+		state.Page--
+	case "next":
+		state.Page++
+	case "last":
+		// Clamped to the last page when rendering
+		state.Page = -1
+	case "period":
+		state.Page = 0
+		if len(data.Values) > 0 {
+			state.Days, _ = strconv.ParseInt(data.Values[0], 10, 64)
+		}
+	}
+
+	return respondLeaderboard(s, i, state, discordgo.InteractionResponseUpdateMessage)
+}
+
+// renderLeaderboard - Build the view for a page, returning the clamped state and page count
+func renderLeaderboard(guildID string, state leaderboardState) (leaderboardView, leaderboardState, int64, error) {
+	view := leaderboardView{}
+	var entries int64
+	var err error
+	switch state.Kind {
+	case leaderboardUsers:
+		entries, err = b.Db.CountUsersForGuild(guildID, state.ChannelID, state.Days)
+	case leaderboardReceivers:
+		entries, err = b.Db.CountReceiversForGuild(guildID, state.ChannelID, state.Days)
+	default:
+		entries, err = b.Db.CountEmojisForGuild(guildID, state.ChannelID, state.Days)
+	}
+	if err != nil {
+		return view, state, 0, err
+	}
+
+	pages := (entries + state.PageSize - 1) / state.PageSize
+	if pages < 1 {
+		pages = 1
+	}
+	if state.Page < 0 || state.Page >= pages {
+		state.Page = pages - 1
+	}
+
+	switch state.Kind {
+	case leaderboardUsers:
+		view, err = renderTopUsers(guildID, state)
+	case leaderboardReceivers:
+		view, err = renderTopReceivers(guildID, state)
+	default:
+		view, err = renderTopEmojis(guildID, state)
+	}
+	if err != nil {
+		return view, state, pages, err
+	}
+
+	view.Offset = state.Page * state.PageSize
+	view.Footer = fmt.Sprintf("%s · page %d/%d", leaderboardPeriodLabel(state.Days), state.Page+1, pages)
+	if state.Kind == leaderboardReceivers {
+		// Older reactions have no recorded author, so percentages are of attributed reactions only
+		view.Total, err = b.Db.CountReceivedForGuild(guildID, state.ChannelID, state.Days)
+	} else {
+		view.Total, err = b.Db.CountUsageForGuild(guildID, state.ChannelID, state.Days)
+	}
+
+	return view, state, pages, err
+}
+
+// renderTopEmojis - Top emojis with users
+func renderTopEmojis(guildID string, state leaderboardState) (leaderboardView, error) {
+	view := leaderboardView{Title: "Most used emojis"}
+	if state.ChannelID != "" {
+		view.Title = fmt.Sprintf("Most used emojis in #%s", channelName(state.ChannelID))
+	}
+
+	top, err := b.Db.GetTopEmojisForGuild(guildID, state.ChannelID, state.Days, state.Page*state.PageSize, state.PageSize)
+	if err != nil {
+		return view, err
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range top {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, v := range keys {
+		topUsers, err := b.Db.GetTopUsersForGuildEmoji(guildID, state.ChannelID, state.Days, top[v].EmojiID, 3)
+		if err != nil {
+			slog.Error("Error getting top users for guild emoji", "err", err)
+			continue
+		}
+
+		subkeys := make([]int, 0)
+		for k := range topUsers {
+			subkeys = append(subkeys, k)
+		}
+		sort.Ints(subkeys)
+
+		row := leaderboardRow{Label: formatEmoji(top[v].EmojiName, top[v].EmojiID), Count: top[v].Count}
+		for _, sv := range subkeys {
+			row.Details = append(row.Details, fmt.Sprintf("<@%s>: %d", topUsers[sv].EmojiID, topUsers[sv].Count))
+		}
+		view.Rows = append(view.Rows, row)
+	}
+
+	return view, nil
+}
+
+// renderTopUsers - Top users with emojis
+func renderTopUsers(guildID string, state leaderboardState) (leaderboardView, error) {
+	view := leaderboardView{Title: "Users who use the most emojis"}
+	if state.ChannelID != "" {
+		view.Title = fmt.Sprintf("Users who use the most emojis in #%s", channelName(state.ChannelID))
+	}
+
+	top, err := b.Db.GetTopUsersForGuild(guildID, state.ChannelID, state.Days, state.Page*state.PageSize, state.PageSize)
+	if err != nil {
+		return view, err
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range top {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, v := range keys {
+		topEmojis, err := b.Db.GetTopEmojisForGuildUser(guildID, state.ChannelID, state.Days, top[v].EmojiID, 3)
+		if err != nil {
+			slog.Error("Error getting top emojis for guild user", "err", err)
+			continue
+		}
+
+		subkeys := make([]int, 0)
+		for k := range topEmojis {
+			subkeys = append(subkeys, k)
+		}
+		sort.Ints(subkeys)
+
+		row := leaderboardRow{Label: fmt.Sprintf("<@%s>", top[v].EmojiID), Count: top[v].Count}
+		for _, sv := range subkeys {
+			row.Details = append(row.Details, fmt.Sprintf("%s %d", formatEmoji(topEmojis[sv].EmojiName, topEmojis[sv].EmojiID), topEmojis[sv].Count))
+		}
+		view.Rows = append(view.Rows, row)
+	}
+
+	return view, nil
+}
+
+// renderTopReceivers - Users whose messages received the most reactions, with the emojis they got
+func renderTopReceivers(guildID string, state leaderboardState) (leaderboardView, error) {
+	view := leaderboardView{Title: "Users who receive the most reactions"}
+	if state.ChannelID != "" {
+		view.Title = fmt.Sprintf("Users who receive the most reactions in #%s", channelName(state.ChannelID))
+	}
+
+	top, err := b.Db.GetTopReceiversForGuild(guildID, state.ChannelID, state.Days, state.Page*state.PageSize, state.PageSize)
+	if err != nil {
+		return view, err
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range top {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, v := range keys {
+		topEmojis, err := b.Db.GetTopEmojisForGuildReceiver(guildID, state.ChannelID, state.Days, top[v].EmojiID, 3)
+		if err != nil {
+			slog.Error("Error getting top emojis for guild receiver", "err", err)
+			continue
+		}
+
+		subkeys := make([]int, 0)
+		for k := range topEmojis {
+			subkeys = append(subkeys, k)
+		}
+		sort.Ints(subkeys)
+
+		row := leaderboardRow{Label: fmt.Sprintf("<@%s>", top[v].EmojiID), Count: top[v].Count}
+		for _, sv := range subkeys {
+			row.Details = append(row.Details, fmt.Sprintf("%s %d", formatEmoji(topEmojis[sv].EmojiName, topEmojis[sv].EmojiID), topEmojis[sv].Count))
+		}
+		view.Rows = append(view.Rows, row)
+	}
+
+	return view, nil
+}
+
+// channelName - Channel name from state, falling back to the ID. Embed titles can't render mentions
+func channelName(channelID string) string {
+	if channel, err := b.DiscordSession.State.Channel(channelID); err == nil {
+		return channel.Name
+	}
+
+	return channelID
+}
+
+// leaderboardPeriodLabel - Human label for a period in days
+func leaderboardPeriodLabel(days int64) string {
+	for _, period := range leaderboardPeriods {
+		if period.Days == days {
+			return period.Label
+		}
+	}
+
+	return fmt.Sprintf("Last %d days", days)
+}
+
+// leaderboardComponents - Page buttons and the period select menu
+func leaderboardComponents(state leaderboardState, pages int64) []discordgo.MessageComponent {
+	periodOptions := []discordgo.SelectMenuOption{}
+	for _, period := range leaderboardPeriods {
+		periodOptions = append(periodOptions, discordgo.SelectMenuOption{
+			Label:   period.Label,
+			Value:   strconv.FormatInt(period.Days, 10),
+			Default: period.Days == state.Days,
+		})
+	}
+
+	first := state.Page == 0
+	last := state.Page >= pages-1
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "«", Style: discordgo.SecondaryButton, CustomID: state.customID("first"), Disabled: first},
+				discordgo.Button{Label: "‹", Style: discordgo.SecondaryButton, CustomID: state.customID("prev"), Disabled: first},
+				discordgo.Button{Label: "›", Style: discordgo.SecondaryButton, CustomID: state.customID("next"), Disabled: last},
+				discordgo.Button{Label: "»", Style: discordgo.SecondaryButton, CustomID: state.customID("last"), Disabled: last},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType: discordgo.StringSelectMenu,
+					CustomID: state.customID("period"),
+					Options:  periodOptions,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// scrubListComponentPrefix - Custom ID prefix routed to handleScrubListComponent
+	scrubListComponentPrefix = "mt"
+
+	scrubListPageSize = 5
+)
+
+// showListScrubs - Paginated list of active scrubs in the guild, only shown to the mod who asked
+func showListScrubs(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	err := deferEphemeral(s, i)
+	if err != nil {
+		return fmt.Errorf("deferring scrub list: %w", err)
+	}
+
+	return respondScrubList(s, i, 0, discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+// handleScrubListComponent - Page buttons, custom IDs look like mt:next:2. The list is ephemeral so only the mod who asked can click them
+func handleScrubListComponent(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid scrub list custom ID %q", i.MessageComponentData().CustomID)
+	}
+
+	page, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing scrub list page: %w", err)
+	}
+
+	switch parts[1] {
+	case "prev":
+		page--
+	case "next":
+		page++
+	}
+
+	return respondScrubList(s, i, page, discordgo.InteractionResponseUpdateMessage)
+}
+
+// respondScrubList - Render a page as a new message or in place of the clicked one
+func respondScrubList(s *discordgo.Session, i *discordgo.InteractionCreate, page int64, responseType discordgo.InteractionResponseType) error {
+	scrubs, err := b.Db.GetActiveScrubsForGuild(i.GuildID, time.Now())
+	if err != nil {
+		return fmt.Errorf("getting active scrubs: %w", err)
+	}
+
+	pages := (int64(len(scrubs)) + scrubListPageSize - 1) / scrubListPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Active magic tools",
+		Description: "None",
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+
+	entries := []string{}
+	for idx := page * scrubListPageSize; idx < int64(len(scrubs)) && idx < (page+1)*scrubListPageSize; idx++ {
+		entries = append(entries, scrubListEntry(scrubs[idx]))
+	}
+	if len(entries) > 0 {
+		embed.Description = truncate(strings.Join(entries, "\n\n"), embedDescriptionLimit)
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d · %d active", page+1, pages, len(scrubs))}
+	}
+
+	components := []discordgo.MessageComponent{}
+	if pages > 1 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "‹", Style: discordgo.SecondaryButton, CustomID: scrubListCustomID("prev", page), Disabled: page == 0},
+				discordgo.Button{Label: "›", Style: discordgo.SecondaryButton, CustomID: scrubListCustomID("next", page), Disabled: page >= pages-1},
+			},
+		})
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			Components:      components,
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+func scrubListCustomID(action string, page int64) string {
+	return fmt.Sprintf("%s:%s:%d", scrubListComponentPrefix, action, page)
+}
+
+// scrubListEntry - Who, what, by whom, when, why, expiry and reactions removed for one scrub
+func scrubListEntry(rule db.Scrub) string {
+	// Scrubs from before this was tracked have neither
+	addedBy := "an unknown mod"
+	switch {
+	case rule.AddedBy != "":
+		addedBy = fmt.Sprintf("<@%s>", rule.AddedBy)
+	case !rule.CreatedAt.IsZero():
+		addedBy = "the spam guard"
+	}
+	if !rule.CreatedAt.IsZero() {
+		addedBy += " " + discordTimestamp(rule.CreatedAt, "R")
+	}
+
+	expires := "Never"
+	if !rule.ExpiresAt.IsZero() {
+		expires = discordTimestamp(rule.ExpiresAt, "R")
+	}
+
+	reason := "No reason given"
+	if rule.Reason != "" {
+		reason = rule.Reason
+	}
+
+	return fmt.Sprintf("**<@%s>** removing %s\nAdded by %s · Expires %s\n%s · %d reactions removed",
+		rule.UserID, describeScrubRule(rule), addedBy, expires, reason, rule.Removed)
+}
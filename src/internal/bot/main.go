@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 
 	"github.com/bwmarrin/discordgo"
@@ -53,15 +54,28 @@ func (bot *Bot) Start() error {
 
 	// Add command handler
 	bot.DiscordSession.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-			h(s, i)
+		switch i.Type {
+		case discordgo.InteractionApplicationCommand:
+			if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+				runWithMiddleware(s, i, h)
+			}
+		case discordgo.InteractionApplicationCommandAutocomplete:
+			if h, ok := autocompleteHandlers[i.ApplicationCommandData().Name]; ok {
+				h(s, i)
+			}
+		case discordgo.InteractionMessageComponent:
+			prefix, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+			if h, ok := componentHandlers[prefix]; ok {
+				runWithMiddleware(s, i, h)
+			}
 		}
 	})
 
 	// Add handlers
-	bot.DiscordSession.AddHandler(bot.HandleAddReaction)
+	bot.DiscordSession.AddHandler(bot.HandleRawEvent)
 	bot.DiscordSession.AddHandler(bot.HandleRemoveReaction)
 	bot.DiscordSession.AddHandler(bot.HandleRemoveAllReaction)
+	bot.DiscordSession.AddHandler(bot.HandleGuildDelete)
 
 	// Load session
 	err = discord.Open()
@@ -87,6 +101,20 @@ func (bot *Bot) Start() error {
 
 	// Add scrubs
 	initScrub()
+	go runScrubSweeper()
+	go runReactionRemovalQueue()
+
+	// Add starboards
+	err = initStarboard()
+	if err != nil {
+		slog.Error("Failed to load starboards", "err", err)
+	}
+
+	// Post scheduled digests
+	go runDigestScheduler()
+
+	// Keep emoji pairs warm for big guilds
+	go runPairsPrecompute()
 
 	// Keep running untill there is NO os interruption (ctrl + C)
 	slog.Info("Bot is now running. Press CTRL-C to exit.")
@@ -100,26 +128,41 @@ func (bot *Bot) Start() error {
 	return nil
 }
 
-// HandleReaction - Simply log it
-func (bot *Bot) HandleAddReaction(discord *discordgo.Session, reaction *discordgo.MessageReactionAdd) {
+// HandleAddReaction - Simply log it
+func (bot *Bot) HandleAddReaction(discord *discordgo.Session, reaction *messageReactionAdd) {
 	// Ignore Dyno user
 	if reaction.UserID == dynoUserID {
 		return
 	}
 
-	if scrub.shouldScrub(reaction.GuildID, reaction.UserID) {
+	// Users already being scrubbed don't feed the spam guard, so one burst raises one alert
+	key := emojiKey(reaction.Emoji.Name, reaction.Emoji.ID)
+	rule, ok := scrub.matchScrub(reaction.GuildID, reaction.UserID, reaction.ChannelID, key)
+	if !ok && checkReactionSpam(reaction) {
+		rule, ok = scrub.matchScrub(reaction.GuildID, reaction.UserID, reaction.ChannelID, key)
+	}
+
+	if ok {
 		err := b.DiscordSession.MessageReactionRemove(reaction.ChannelID, reaction.MessageID, reaction.Emoji.APIName(), reaction.UserID)
 		if err == nil {
+			err = bot.Db.IncrementScrubRemoved(rule.ID)
+			if err != nil {
+				slog.Error("Failed to count scrubbed reaction", "err", err, "id", rule.ID)
+			}
 			return
 		}
 
 		slog.Error("Failed to remove emoji reaction", "err", err, "reaction", reaction)
 	}
 
-	err := bot.Db.LogEmojiUsage(reaction.GuildID, reaction.ChannelID, reaction.MessageID, reaction.UserID, reaction.Emoji.ID, reaction.Emoji.Name)
+	err := bot.Db.LogEmojiUsage(reaction.GuildID, reaction.ChannelID, reaction.MessageID, reaction.UserID, reaction.Emoji.ID, reaction.Emoji.Name, messageAuthor(reaction))
 	if err != nil {
 		slog.Error("Failed to log emoji usage", "err", err)
+		return
 	}
+
+	achievements.check(reaction.GuildID, reaction.UserID)
+	starboards.evaluate(reaction.GuildID, reaction.ChannelID, reaction.MessageID, key)
 }
 
 // HandleRemoveReaction - Remove for user/message/emoji
@@ -132,7 +175,10 @@ func (bot *Bot) HandleRemoveReaction(discord *discordgo.Session, reaction *disco
 	err := bot.Db.DeleteEmojiUsage(reaction.GuildID, reaction.ChannelID, reaction.MessageID, reaction.UserID, reaction.Emoji.ID)
 	if err != nil {
 		slog.Error("Failed to delete single emoji usage", "err", err)
+		return
 	}
+
+	starboards.evaluate(reaction.GuildID, reaction.ChannelID, reaction.MessageID, emojiKey(reaction.Emoji.Name, reaction.Emoji.ID))
 }
 
 // HandleRemoveAllReaction - Remove all for message
@@ -140,5 +186,20 @@ func (bot *Bot) HandleRemoveAllReaction(discord *discordgo.Session, reaction *di
 	err := bot.Db.DeleteEmojiAll(reaction.GuildID, reaction.ChannelID, reaction.MessageID)
 	if err != nil {
 		slog.Error("Failed to delete all emoji usage for message", "err", err)
+		return
 	}
+
+	if starboard, ok := starboards.get(reaction.GuildID); ok {
+		starboards.evaluate(reaction.GuildID, reaction.ChannelID, reaction.MessageID, starboard.Emoji)
+	}
+}
+
+// HandleGuildDelete - Forget cached data for guilds the bot was removed from
+func (bot *Bot) HandleGuildDelete(discord *discordgo.Session, guild *discordgo.GuildDelete) {
+	// Outages also send this, the guild comes back when they end
+	if guild.Unavailable {
+		return
+	}
+
+	pairs.removeGuild(guild.ID)
 }
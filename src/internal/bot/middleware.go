@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// deferAfter - Discord drops interactions that aren't acknowledged within 3 seconds
+const deferAfter = 2 * time.Second
+
+type interactionHandler func(s *discordgo.Session, i *discordgo.InteractionCreate) error
+
+// interactionState - Tracks whether the middleware deferred an interaction on the handler's behalf
+type interactionState struct {
+	mutex     sync.Mutex
+	deferred  bool
+	responded bool
+
+	// public - Deferred with a new message everyone can see, so ephemeral replies can't edit it
+	public bool
+}
+
+// pendingInteractions - map[InteractionID]*interactionState for interactions being handled
+var pendingInteractions sync.Map
+
+// newCorrelationID - Short random ID shown to users and logged alongside errors
+func newCorrelationID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+// interactionName - Command name or component custom ID, for logging
+func interactionName(i *discordgo.InteractionCreate) string {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		return i.ApplicationCommandData().Name
+	case discordgo.InteractionMessageComponent:
+		return i.MessageComponentData().CustomID
+	}
+
+	return ""
+}
+
+// runWithMiddleware - Run a handler, deferring the response if it is slow and
+// reporting failures to the user with a correlation ID
+func runWithMiddleware(s *discordgo.Session, i *discordgo.InteractionCreate, h interactionHandler) {
+	correlationID := newCorrelationID()
+	logger := slog.With("correlation_id", correlationID, "interaction", interactionName(i), "guild_id", i.GuildID)
+
+	state := &interactionState{}
+	pendingInteractions.Store(i.ID, state)
+	defer pendingInteractions.Delete(i.ID)
+
+	// Components update the message they are on, commands get a new one
+	deferType := discordgo.InteractionResponseDeferredChannelMessageWithSource
+	if i.Type == discordgo.InteractionMessageComponent {
+		deferType = discordgo.InteractionResponseDeferredMessageUpdate
+	}
+
+	timer := time.AfterFunc(deferAfter, func() {
+		state.mutex.Lock()
+		defer state.mutex.Unlock()
+		if state.responded {
+			return
+		}
+
+		err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: deferType})
+		if err != nil {
+			logger.Error("Failed to defer interaction", "err", err)
+			return
+		}
+
+		state.deferred = true
+		state.public = deferType == discordgo.InteractionResponseDeferredChannelMessageWithSource
+		logger.Info("Deferred slow interaction")
+	})
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		return h(s, i)
+	}()
+	timer.Stop()
+
+	if err == nil {
+		return
+	}
+
+	logger.Error("Interaction failed", "err", err)
+	respondError(s, i, state, correlationID)
+}
+
+// respondError - Tell the user something went wrong, privately
+func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, state *interactionState, correlationID string) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	content := fmt.Sprintf(":x: Something went wrong. Reference: `%s`", correlationID)
+	var err error
+	switch {
+	case state.deferred && i.Type == discordgo.InteractionApplicationCommand && !state.responded:
+		// Drop the "thinking" placeholder so the error can be ephemeral
+		s.InteractionResponseDelete(i.Interaction)
+		_, err = followup(s, i, &discordgo.WebhookParams{Content: content, Flags: discordgo.MessageFlagsEphemeral})
+	case state.deferred || state.responded:
+		_, err = followup(s, i, &discordgo.WebhookParams{Content: content, Flags: discordgo.MessageFlagsEphemeral})
+	default:
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if err != nil {
+		slog.Error("Failed to send error response", "err", err, "correlation_id", correlationID)
+	}
+}
+
+// respond - Send the response for an interaction, editing the deferred response if the middleware already acknowledged it
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, resp *discordgo.InteractionResponse) error {
+	value, ok := pendingInteractions.Load(i.ID)
+	if !ok {
+		return s.InteractionRespond(i.Interaction, resp)
+	}
+
+	state := value.(*interactionState)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if !state.deferred {
+		err := s.InteractionRespond(i.Interaction, resp)
+		if err == nil {
+			state.responded = true
+		}
+		return err
+	}
+
+	// Editing a public placeholder would make an ephemeral reply public, replace it with an ephemeral followup
+	if state.public && resp.Data != nil && resp.Data.Flags&discordgo.MessageFlagsEphemeral != 0 {
+		err := s.InteractionResponseDelete(i.Interaction)
+		if err != nil {
+			slog.Error("Failed to delete deferred response", "err", err, "guild_id", i.GuildID)
+		}
+
+		_, err = followup(s, i, &discordgo.WebhookParams{
+			Content:         resp.Data.Content,
+			Embeds:          resp.Data.Embeds,
+			Components:      resp.Data.Components,
+			Files:           resp.Data.Files,
+			AllowedMentions: resp.Data.AllowedMentions,
+			Flags:           resp.Data.Flags,
+		})
+		if err == nil {
+			state.responded = true
+		}
+		return err
+	}
+
+	edit := &discordgo.WebhookEdit{}
+	if data := resp.Data; data != nil {
+		edit.Content = &data.Content
+		edit.Embeds = &data.Embeds
+		edit.Components = &data.Components
+		edit.Files = data.Files
+		edit.AllowedMentions = data.AllowedMentions
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, edit)
+	if err == nil {
+		state.responded = true
+	}
+
+	return err
+}
+
+// followup - Send an extra message after the interaction has been acknowledged
+func followup(s *discordgo.Session, i *discordgo.InteractionCreate, params *discordgo.WebhookParams) (*discordgo.Message, error) {
+	return s.FollowupMessageCreate(i.Interaction, true, params)
+}
+
+// deferEphemeral - Acknowledge now so only the user sees the response, respond then edits it in place
+func deferEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	value, ok := pendingInteractions.Load(i.ID)
+	if !ok {
+		return fmt.Errorf("interaction %s is not being handled", i.ID)
+	}
+
+	state := value.(*interactionState)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if state.deferred || state.responded {
+		return nil
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	})
+	if err == nil {
+		state.deferred = true
+	}
+
+	return err
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// breakdownAverageDays - Period the channel average is taken over
+	breakdownAverageDays = 90
+
+	breakdownMoreName  = "More"
+	breakdownMoreValue = "…and %d more emojis"
+)
+
+// showReactionBreakdown - Message context-menu command, who reacted with what and how the message compares to its channel
+func showReactionBreakdown(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	messageID := data.TargetID
+	channelID := i.ChannelID
+
+	reactions, err := b.Db.GetReactionsForMessage(i.GuildID, messageID)
+	if err != nil {
+		return fmt.Errorf("getting reactions for message: %w", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Reaction breakdown",
+		URL:   fmt.Sprintf("https://discord.com/channels/%s/%s/%s", i.GuildID, channelID, messageID),
+	}
+	if data.Resolved != nil {
+		if message, ok := data.Resolved.Messages[messageID]; ok && message.Author != nil {
+			embed.Author = &discordgo.MessageEmbedAuthor{Name: message.Author.Username, IconURL: message.Author.AvatarURL("")}
+			embed.Description = truncate(message.Content, 200)
+		}
+	}
+
+	if len(reactions) == 0 {
+		embed.Description = "No reactions recorded on this message"
+		return respondBreakdown(s, i, embed)
+	}
+
+	// Group by emoji in the order they were first used
+	order := []string{}
+	groups := make(map[string][]db.EmojiUsage)
+	users := make(map[string]bool)
+	for _, reaction := range reactions {
+		key := emojiKey(reaction.EmojiName, reaction.EmojiID)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], reaction)
+		users[reaction.UserID] = true
+	}
+
+	stats, err := b.Db.GetReactionStatsForChannel(i.GuildID, channelID, breakdownAverageDays, int64(len(reactions)))
+	if err != nil {
+		slog.Error("Error getting channel reaction stats", "err", err)
+	}
+
+	comparison := fmt.Sprintf("%d reactions from %d people", len(reactions), len(users))
+	if stats.Messages > 0 && stats.Average > 0 {
+		comparison += fmt.Sprintf(
+			"\n<#%s> averages %.1f per reacted message over %d days\nThis one has %.1f× that, more than %.0f%% of them",
+			channelID,
+			stats.Average,
+			breakdownAverageDays,
+			float64(len(reactions))/stats.Average,
+			float64(stats.Fewer)/float64(stats.Messages)*100,
+		)
+	}
+	comparisonField := &discordgo.MessageEmbedField{Name: "Compared to the channel", Value: comparison}
+
+	// Everything except the emoji fields counts towards the total, plus room for the "more" line
+	size := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description) +
+		utf8.RuneCountInString(comparisonField.Name) + utf8.RuneCountInString(comparisonField.Value) +
+		utf8.RuneCountInString(breakdownMoreName) + utf8.RuneCountInString(fmt.Sprintf(breakdownMoreValue, len(order)))
+	if embed.Author != nil {
+		size += utf8.RuneCountInString(embed.Author.Name)
+	}
+
+	// Leave fields free for the "more" line and the comparison
+	for idx, key := range order {
+		group := groups[key]
+		lines := []string{}
+		for n, reaction := range group {
+			line := fmt.Sprintf("<@%s> %s", reaction.UserID, discordTimestamp(reaction.Timestamp, "R"))
+			more := fmt.Sprintf("…and %d more", len(group)-n)
+			if len(strings.Join(append(lines, line, more), "\n")) > embedFieldValueLimit {
+				lines = append(lines, more)
+				break
+			}
+			lines = append(lines, line)
+		}
+
+		field := &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%s %d", emojiFieldName(group[0]), len(group)),
+			Value:  strings.Join(lines, "\n"),
+			Inline: true,
+		}
+		fieldSize := utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		tooMany := len(order) > embedFieldLimit-1 && idx >= embedFieldLimit-2
+		if tooMany || size+fieldSize > embedTotalLimit {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  breakdownMoreName,
+				Value: fmt.Sprintf(breakdownMoreValue, len(order)-idx),
+			})
+			break
+		}
+
+		embed.Fields = append(embed.Fields, field)
+		size += fieldSize
+	}
+	embed.Fields = append(embed.Fields, comparisonField)
+
+	return respondBreakdown(s, i, embed)
+}
+
+// emojiFieldName - Embed field names can't render custom emojis, so show those by name
+func emojiFieldName(usage db.EmojiUsage) string {
+	if usage.EmojiID == "" {
+		return usage.EmojiName
+	}
+
+	return ":" + usage.EmojiName + ":"
+}
+
+// respondBreakdown - Send a breakdown embed
+func respondBreakdown(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
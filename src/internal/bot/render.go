@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord embed limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	embedTitleLimit       = 256
+	embedDescriptionLimit = 4096
+	embedFieldLimit       = 25
+	embedFieldNameLimit   = 256
+	embedFieldValueLimit  = 1024
+	embedFooterLimit      = 2048
+	embedTotalLimit       = 6000
+	embedsPerMessage      = 10
+)
+
+var rankMedals = []string{"🥇", "🥈", "🥉"}
+
+type leaderboardRow struct {
+	Label   string
+	Count   int64
+	Details []string
+}
+
+// leaderboardView - A rendered leaderboard, independent of how it gets sent
+type leaderboardView struct {
+	Title  string
+	Footer string
+	Rows   []leaderboardRow
+	// Offset - Rank of the first row minus one, for paged views
+	Offset int64
+	// Total - Uses across the whole scope, for percentages
+	Total int64
+}
+
+// rankLabel - Medal for the podium, #n after that
+func rankLabel(rank int64) string {
+	if rank >= 1 && rank <= int64(len(rankMedals)) {
+		return rankMedals[rank-1]
+	}
+
+	return fmt.Sprintf("#%d", rank)
+}
+
+// truncate - Cut a string to a number of characters, marking the cut with an ellipsis
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
+
+// joinRows - Join rows with newlines, stopping at a row boundary with "…and N more" if they'd pass the limit
+func joinRows(rows []string, limit int) string {
+	if len(rows) == 0 {
+		return "None"
+	}
+
+	msg := ""
+	for idx, row := range rows {
+		more := fmt.Sprintf("…and %d more", len(rows)-idx)
+		if utf8.RuneCountInString(msg+row+"\n"+more) > limit {
+			return msg + more
+		}
+		msg += row + "\n"
+	}
+
+	return strings.TrimSuffix(msg, "\n")
+}
+
+// percent - Share of the total, guarding against an empty total
+func (v leaderboardView) percent(count int64) float64 {
+	if v.Total == 0 {
+		return 0
+	}
+
+	return float64(count) / float64(v.Total) * 100
+}
+
+// Embeds - One field per row, split across embeds of 25 fields. Returns nil if it can't fit in a message
+func (v leaderboardView) Embeds() []*discordgo.MessageEmbed {
+	embeds := []*discordgo.MessageEmbed{}
+	size := 0
+	var embed *discordgo.MessageEmbed
+	for idx, row := range v.Rows {
+		if embed == nil || len(embed.Fields) >= embedFieldLimit {
+			embed = &discordgo.MessageEmbed{}
+			embeds = append(embeds, embed)
+		}
+
+		rank := v.Offset + int64(idx) + 1
+		name := truncate(fmt.Sprintf("%s  %d (%.1f%%)", rankLabel(rank), row.Count, v.percent(row.Count)), embedFieldNameLimit)
+		value := row.Label
+		if len(row.Details) > 0 {
+			value += "\n" + strings.Join(row.Details, ", ")
+		}
+		value = truncate(value, embedFieldValueLimit)
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+		size += utf8.RuneCountInString(name) + utf8.RuneCountInString(value)
+	}
+
+	if len(embeds) == 0 {
+		embeds = append(embeds, &discordgo.MessageEmbed{Description: "Nothing recorded yet"})
+	}
+
+	embeds[0].Title = truncate(v.Title, embedTitleLimit)
+	embeds[len(embeds)-1].Footer = &discordgo.MessageEmbedFooter{
+		Text: truncate(fmt.Sprintf("%s · %d total", v.Footer, v.Total), embedFooterLimit),
+	}
+	size += utf8.RuneCountInString(embeds[0].Title) + utf8.RuneCountInString(embeds[len(embeds)-1].Footer.Text)
+
+	if size > embedTotalLimit || len(embeds) > embedsPerMessage {
+		return nil
+	}
+
+	return embeds
+}
+
+// Text - Plain text fallback, cut at a row boundary to fit in a message
+func (v leaderboardView) Text() string {
+	footer := fmt.Sprintf("-# %s · %d total", v.Footer, v.Total)
+	msg := v.Title + "\n"
+	if len(v.Rows) == 0 {
+		msg += "Nothing recorded yet\n"
+	}
+
+	for idx, row := range v.Rows {
+		rank := v.Offset + int64(idx) + 1
+		line := fmt.Sprintf("%s %s %d (%.1f%%)", rankLabel(rank), row.Label, row.Count, v.percent(row.Count))
+		if len(row.Details) > 0 {
+			line += "  (" + strings.Join(row.Details, ", ") + ")"
+		}
+		line += "\n"
+
+		more := fmt.Sprintf("…and %d more\n", len(v.Rows)-idx)
+		if utf8.RuneCountInString(msg+line+more+footer) > maxMessageLength {
+			msg += more
+			break
+		}
+		msg += line
+	}
+
+	return truncate(msg+footer, maxMessageLength)
+}
+
+// respondLeaderboardView - Send a view as embeds, falling back to plain text if they don't fit or are rejected
+func respondLeaderboardView(s *discordgo.Session, i *discordgo.InteractionCreate, view leaderboardView, components []discordgo.MessageComponent, responseType discordgo.InteractionResponseType) error {
+	if embeds := view.Embeds(); embeds != nil {
+		err := respond(s, i, &discordgo.InteractionResponse{
+			Type: responseType,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "",
+				Embeds:          embeds,
+				Components:      components,
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+		if err == nil {
+			return nil
+		}
+
+		slog.Error("Error sending leaderboard embeds, falling back to text", "err", err, "guild_id", i.GuildID)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Content:         view.Text(),
+			Embeds:          []*discordgo.MessageEmbed{},
+			Components:      components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
@@ -1,37 +1,89 @@
 package bot
 
 import (
+	"fmt"
 	"log/slog"
+	"regexp"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
 )
 
+// scrubSweepInterval - How often expired scrubs are cleaned up
+const scrubSweepInterval = time.Minute
+
+// scrubDurationRegex - One or more number and unit pairs, e.g. 30m, 1h, 7d or 1d12h
+var scrubDurationRegex = regexp.MustCompile(`(\d+)([mhdw])`)
+
+// maxScrubDuration - Longest temporary scrub, anything longer should be permanent
+const maxScrubDuration = 365 * 24 * time.Hour
+
+var scrubDurationUnits = map[string]time.Duration{
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
 type Scrubber struct {
-	scrubs map[string]map[string]bool
+	// scrubs map[GuildID][UserID][]Rule
+	scrubs map[string]map[string][]db.Scrub
 	mutex  sync.RWMutex
 }
 
 var scrub *Scrubber
 
-// shouldScrub - Check if a user is scrubbing in a guild
-func (s *Scrubber) shouldScrub(guildID string, userID string) bool {
+// scrubRuleMatches - Whether a rule applies to a reaction. Threads match rules for their parent channel
+func scrubRuleMatches(rule db.Scrub, channelID string, parentID string, emoji string, now time.Time) bool {
+	// Expired but not swept yet
+	if !rule.ExpiresAt.IsZero() && !now.Before(rule.ExpiresAt) {
+		return false
+	}
+
+	if rule.ChannelID != "" && rule.ChannelID != channelID && rule.ChannelID != parentID {
+		return false
+	}
+
+	return rule.Emoji == "" || emojiModifiers.Replace(rule.Emoji) == emojiModifiers.Replace(emoji)
+}
+
+// sameScrubScope - Rules for the same emoji and channel replace each other
+func sameScrubScope(a db.Scrub, other db.Scrub) bool {
+	return a.Emoji == other.Emoji && a.ChannelID == other.ChannelID
+}
+
+// matchScrub - First of a user's rules that matches a reaction
+func (s *Scrubber) matchScrub(guildID string, userID string, channelID string, emoji string) (db.Scrub, bool) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	if _, ok := s.scrubs[guildID]; !ok {
-		return false
+	rules, ok := s.scrubs[guildID][userID]
+	if !ok {
+		return db.Scrub{}, false
 	}
 
-	if _, ok := s.scrubs[guildID][userID]; !ok {
-		return false
+	parentID := ""
+	if channel, err := b.DiscordSession.State.Channel(channelID); err == nil && channel.IsThread() {
+		parentID = channel.ParentID
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if scrubRuleMatches(rule, channelID, parentID, emoji, now) {
+			return rule, true
+		}
 	}
 
-	return true
+	return db.Scrub{}, false
 }
 
 // initScrub - Loads the scrubber configs from the DB
 func initScrub() error {
 	s := Scrubber{
-		scrubs: make(map[string]map[string]bool),
+		scrubs: make(map[string]map[string][]db.Scrub),
 		mutex:  sync.RWMutex{},
 	}
 	scrub = &s
@@ -42,14 +94,14 @@ func initScrub() error {
 		return err
 	}
 
-	// map[GuildID][UserID]bool
-	scrubs := make(map[string]map[string]bool)
+	// map[GuildID][UserID][]Rule
+	scrubs := make(map[string]map[string][]db.Scrub)
 	for _, scrubber := range allScrubbers {
 		if _, ok := scrubs[scrubber.GuildID]; !ok {
-			scrubs[scrubber.GuildID] = make(map[string]bool)
+			scrubs[scrubber.GuildID] = make(map[string][]db.Scrub)
 		}
 
-		scrubs[scrubber.GuildID][scrubber.UserID] = true
+		scrubs[scrubber.GuildID][scrubber.UserID] = append(scrubs[scrubber.GuildID][scrubber.UserID], scrubber)
 	}
 
 	scrub.scrubs = scrubs
@@ -57,24 +109,45 @@ func initScrub() error {
 	return nil
 }
 
-// startScrubbingUser - Start an auto scrubber for a user in a guild
-func (s *Scrubber) startScrubbingUser(guildID string, userID string) error {
-	err := b.Db.AddScrub(guildID, userID)
+// startScrubbingUser - Add a rule for a user in a guild, replacing any with the same emoji and channel.
+// A zero ExpiresAt scrubs until removed
+func (s *Scrubber) startScrubbingUser(rule db.Scrub) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.addRule(rule)
+}
+
+// addRule - Save a rule and swap it into the map. Caller holds the lock
+func (s *Scrubber) addRule(rule db.Scrub) error {
+	_, err := b.Db.RemoveScrubRule(rule.GuildID, rule.UserID, rule.Emoji, rule.ChannelID)
+	if err != nil {
+		slog.Error("Failed to replace auto scrubber", "err", err)
+		return err
+	}
+
+	rule.ID, err = b.Db.AddScrub(rule)
 	if err != nil {
 		slog.Error("Failed to add auto scrubber", "err", err)
 		return err
 	}
 
 	// Add to map
-	if _, ok := s.scrubs[guildID]; !ok {
-		s.scrubs[guildID] = make(map[string]bool)
+	if _, ok := s.scrubs[rule.GuildID]; !ok {
+		s.scrubs[rule.GuildID] = make(map[string][]db.Scrub)
+	}
+	rules := []db.Scrub{}
+	for _, existing := range s.scrubs[rule.GuildID][rule.UserID] {
+		if !sameScrubScope(existing, rule) {
+			rules = append(rules, existing)
+		}
 	}
-	s.scrubs[guildID][userID] = true
+	s.scrubs[rule.GuildID][rule.UserID] = append(rules, rule)
 
 	return nil
 }
 
-// stopScrubbingUser - Stop an auto scrubber for a user in a guild
+// stopScrubbingUser - Remove every rule for a user in a guild
 func (s *Scrubber) stopScrubbingUser(guildID string, userID string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -82,3 +155,168 @@ func (s *Scrubber) stopScrubbingUser(guildID string, userID string) error {
 	delete(s.scrubs[guildID], userID)
 	return b.Db.RemoveScrub(guildID, userID)
 }
+
+// stopScrubbingRule - Remove the rule for a user with exactly this emoji and channel, false if there wasn't one
+func (s *Scrubber) stopScrubbingRule(guildID string, userID string, emoji string, channelID string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	removed, err := b.Db.RemoveScrubRule(guildID, userID, emoji, channelID)
+	if err != nil {
+		return false, err
+	}
+
+	scope := db.Scrub{Emoji: emoji, ChannelID: channelID}
+	rules := []db.Scrub{}
+	for _, existing := range s.scrubs[guildID][userID] {
+		if !sameScrubScope(existing, scope) {
+			rules = append(rules, existing)
+		}
+	}
+	s.setRules(guildID, userID, rules)
+
+	return removed, nil
+}
+
+// setRules - Replace a user's rules, dropping them from the map when there are none left. Caller holds the lock
+func (s *Scrubber) setRules(guildID string, userID string, rules []db.Scrub) {
+	if len(rules) == 0 {
+		delete(s.scrubs[guildID], userID)
+		return
+	}
+
+	s.scrubs[guildID][userID] = rules
+}
+
+// scrubTemporarily - Scrub every reaction from a user until a time, used by the spam guard.
+// Never shortens an existing rule for every emoji and channel, or replaces one a mod added.
+// Returns the rule in effect afterwards and whether it was added by this call
+func (s *Scrubber) scrubTemporarily(guildID string, userID string, until time.Time, reason string) (db.Scrub, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, rule := range s.scrubs[guildID][userID] {
+		if rule.Emoji != "" || rule.ChannelID != "" {
+			continue
+		}
+		if rule.AddedBy != "" || rule.ExpiresAt.IsZero() || rule.ExpiresAt.After(until) {
+			return rule, false, nil
+		}
+	}
+
+	rule := db.Scrub{GuildID: guildID, UserID: userID, ExpiresAt: until, Reason: reason}
+	err := s.addRule(rule)
+	if err != nil {
+		return rule, false, err
+	}
+
+	return rule, true, nil
+}
+
+// runScrubSweeper - Remove expired scrubs every minute
+func runScrubSweeper() {
+	ticker := time.NewTicker(scrubSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		scrub.sweep(time.Now())
+	}
+}
+
+// sweep - Drop expired scrubs from the DB and the map and tell whoever added them
+func (s *Scrubber) sweep(now time.Time) {
+	expired, err := b.Db.GetExpiredScrubs(now)
+	if err != nil {
+		slog.Error("Failed to get expired scrubs", "err", err)
+		return
+	}
+
+	for _, expiredScrub := range expired {
+		s.mutex.Lock()
+		removed, err := b.Db.RemoveScrubByID(expiredScrub.ID)
+		if err == nil {
+			rules := []db.Scrub{}
+			for _, existing := range s.scrubs[expiredScrub.GuildID][expiredScrub.UserID] {
+				if existing.ID != expiredScrub.ID {
+					rules = append(rules, existing)
+				}
+			}
+			s.setRules(expiredScrub.GuildID, expiredScrub.UserID, rules)
+		}
+		s.mutex.Unlock()
+
+		if err != nil {
+			slog.Error("Failed to remove expired scrub", "err", err, "id", expiredScrub.ID)
+			continue
+		}
+
+		// Replaced or removed by someone else in the meantime
+		if !removed || expiredScrub.AddedBy == "" {
+			continue
+		}
+
+		notifyScrubExpired(expiredScrub)
+	}
+}
+
+// describeScrubRule - What a rule removes, e.g. "🤡 in #general"
+func describeScrubRule(rule db.Scrub) string {
+	what := "every reaction"
+	if rule.Emoji != "" {
+		what = formatEmojiKey(rule.GuildID, rule.Emoji)
+	}
+	if rule.ChannelID != "" {
+		what += fmt.Sprintf(" in <#%s>", rule.ChannelID)
+	}
+
+	return what
+}
+
+// notifyScrubExpired - DM the mod who added a scrub that it has ended
+func notifyScrubExpired(rule db.Scrub) {
+	channel, err := b.DiscordSession.UserChannelCreate(rule.AddedBy)
+	if err != nil {
+		slog.Error("Failed to open DM for expired scrub", "err", err, "user_id", rule.AddedBy)
+		return
+	}
+
+	_, err = b.DiscordSession.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Content:         fmt.Sprintf("The magic tool you added for <@%s> (%s) in %s has expired", rule.UserID, describeScrubRule(rule), guildName(rule.GuildID)),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		slog.Error("Failed to send expired scrub DM", "err", err, "user_id", rule.AddedBy)
+	}
+}
+
+// parseScrubDuration - Parse durations like 30m, 1h, 7d, 2w or 1d12h, up to a year
+func parseScrubDuration(value string) (time.Duration, error) {
+	matches := scrubDurationRegex.FindAllStringSubmatch(value, -1)
+
+	matched := ""
+	duration := time.Duration(0)
+	for _, m := range matches {
+		amount, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+
+		// Check each part before multiplying and the total as it grows so huge values can't overflow
+		unit := scrubDurationUnits[m[2]]
+		if amount > int(maxScrubDuration/unit) {
+			return 0, fmt.Errorf("duration %q is longer than %s", value, maxScrubDuration)
+		}
+
+		matched += m[0]
+		duration += time.Duration(amount) * unit
+		if duration > maxScrubDuration {
+			return 0, fmt.Errorf("duration %q is longer than %s", value, maxScrubDuration)
+		}
+	}
+
+	if matched != value || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return duration, nil
+}
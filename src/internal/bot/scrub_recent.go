@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// reactionRemovalInterval - Space out removals so a big scrub doesn't use up the rate limit for everything else
+	reactionRemovalInterval = 300 * time.Millisecond
+
+	// scrubProgressInterval - How often the progress message is edited
+	scrubProgressInterval = 5 * time.Second
+
+	// interactionTokenLifetime - Responses can't be edited after this, with a little slack
+	interactionTokenLifetime = 14 * time.Minute
+)
+
+type reactionRemoval struct {
+	usage  db.EmojiUsage
+	result chan<- error
+}
+
+// reactionRemovals - Queue shared by every scrub so they take turns
+var reactionRemovals = make(chan reactionRemoval, 100)
+
+// errReactionGone - The message or reaction was already deleted
+var errReactionGone = errors.New("reaction already gone")
+
+// runReactionRemovalQueue - Remove queued reactions one at a time
+func runReactionRemovalQueue() {
+	ticker := time.NewTicker(reactionRemovalInterval)
+	defer ticker.Stop()
+
+	for removal := range reactionRemovals {
+		<-ticker.C
+		removal.result <- removeRecordedReaction(removal.usage)
+	}
+}
+
+// removeRecordedReaction - Remove a logged reaction from Discord and drop its emoji_usage row
+func removeRecordedReaction(usage db.EmojiUsage) error {
+	emoji := discordgo.Emoji{ID: usage.EmojiID, Name: usage.EmojiName}
+	if emoji.ID == emoji.Name {
+		// Older rows stored stock emojis with their name as the ID
+		emoji.ID = ""
+	}
+	err := b.DiscordSession.MessageReactionRemove(usage.ChannelID, usage.MessageID, emoji.APIName(), usage.UserID)
+
+	var restErr *discordgo.RESTError
+	gone := errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+	if err != nil && !gone {
+		return err
+	}
+
+	dbErr := b.Db.DeleteEmojiUsageById(usage.ID)
+	if dbErr != nil {
+		slog.Error("Failed to delete scrubbed emoji usage", "err", dbErr, "id", usage.ID)
+	}
+
+	if gone {
+		return errReactionGone
+	}
+
+	return nil
+}
+
+type scrubRecentProgress struct {
+	Total   int
+	Removed int
+	Gone    int
+	Failed  int
+}
+
+func (p scrubRecentProgress) done() int {
+	return p.Removed + p.Gone + p.Failed
+}
+
+// handleScrubRecent - Remove a user's recent reactions
+func handleScrubRecent(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	user := optionMap["user"].UserValue(nil)
+	hours := optionMap["hours"].IntValue()
+
+	emoji := ""
+	if opt, ok := optionMap["emoji"]; ok {
+		emoji = parseEmojiKey(i.GuildID, opt.StringValue())
+	}
+
+	recent, err := b.Db.GetRecentEmojisForUser(i.GuildID, user.ID, hours)
+	if err != nil {
+		return fmt.Errorf("getting recent emojis for user: %w", err)
+	}
+
+	usages := []db.EmojiUsage{}
+	for _, usage := range recent {
+		if emoji == "" || emojiModifiers.Replace(emojiKey(usage.EmojiName, usage.EmojiID)) == emojiModifiers.Replace(emoji) {
+			usages = append(usages, usage)
+		}
+	}
+
+	what := "reactions"
+	if emoji != "" {
+		what = formatEmojiKey(i.GuildID, emoji) + " reactions"
+	}
+
+	if len(usages) == 0 {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         fmt.Sprintf("<@%s> has no recorded %s in the last %d hours", user.ID, what, hours),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	progress := scrubRecentProgress{Total: len(usages)}
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         scrubRecentMessage(user.ID, what, hours, progress),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Carry on after the handler returns, the interaction token stays valid for 15 minutes
+	go runScrubRecent(s, i, user.ID, what, hours, usages)
+
+	return nil
+}
+
+// runScrubRecent - Queue the removals and keep the response up to date
+func runScrubRecent(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, what string, hours int64, usages []db.EmojiUsage) {
+	results := make(chan error, len(usages))
+	go func() {
+		for _, usage := range usages {
+			reactionRemovals <- reactionRemoval{usage: usage, result: results}
+		}
+	}()
+
+	progress := scrubRecentProgress{Total: len(usages)}
+	tokenExpires := time.Now().Add(interactionTokenLifetime)
+	lastUpdate := time.Now()
+	for progress.done() < progress.Total {
+		err := <-results
+		switch {
+		case err == nil:
+			progress.Removed++
+		case errors.Is(err, errReactionGone):
+			progress.Gone++
+		default:
+			progress.Failed++
+			slog.Error("Failed to remove recent reaction", "err", err, "guild_id", i.GuildID, "user_id", userID)
+		}
+
+		if time.Since(lastUpdate) >= scrubProgressInterval && progress.done() < progress.Total && time.Now().Before(tokenExpires) {
+			editScrubRecent(s, i, scrubRecentMessage(userID, what, hours, progress))
+			lastUpdate = time.Now()
+		}
+	}
+
+	content := scrubRecentMessage(userID, what, hours, progress)
+	if time.Now().Before(tokenExpires) && editScrubRecent(s, i, content) == nil {
+		return
+	}
+
+	// Too late to edit the response, post the result on its own
+	_, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		slog.Error("Failed to send scrub result", "err", err, "guild_id", i.GuildID, "channel_id", i.ChannelID)
+	}
+}
+
+// editScrubRecent - Replace the response with the latest progress
+func editScrubRecent(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:         &content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		slog.Error("Failed to update scrub progress", "err", err, "guild_id", i.GuildID)
+	}
+
+	return err
+}
+
+// scrubRecentMessage - Progress while running, results once everything has been tried
+func scrubRecentMessage(userID string, what string, hours int64, progress scrubRecentProgress) string {
+	if progress.done() < progress.Total {
+		return fmt.Sprintf(":hourglass: Removing <@%s>'s %s from the last %d hours: %d/%d done",
+			userID, what, hours, progress.done(), progress.Total)
+	}
+
+	return fmt.Sprintf(":white_check_mark: Removed %d of <@%s>'s %s from the last %d hours (%d already gone, %d failed)",
+		progress.Removed, userID, what, hours, progress.Gone, progress.Failed)
+}
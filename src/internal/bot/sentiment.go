@@ -0,0 +1,317 @@
+package bot
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	sentimentPositive = "positive"
+	sentimentNegative = "negative"
+	sentimentNeutral  = "neutral"
+
+	moodBarWidth = 10
+)
+
+//go:embed sentiment_lexicon.txt
+var sentimentLexiconSource string
+
+// sentimentLexicon - Default sentiment for Unicode emoji keyed by normalised emoji
+var sentimentLexicon = parseSentimentLexicon(sentimentLexiconSource)
+
+// emojiModifiers - Variation selectors and skin tones don't change an emoji's sentiment
+var emojiModifiers = strings.NewReplacer(
+	"\uFE0E", "", "\uFE0F", "",
+	"\U0001F3FB", "", "\U0001F3FC", "", "\U0001F3FD", "", "\U0001F3FE", "", "\U0001F3FF", "",
+)
+
+// parseSentimentLexicon - Each line is a sentiment followed by its emojis, # starts a comment
+func parseSentimentLexicon(source string) map[string]string {
+	lexicon := make(map[string]string)
+	for _, line := range strings.Split(source, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		for _, emoji := range fields[1:] {
+			lexicon[emojiModifiers.Replace(emoji)] = fields[0]
+		}
+	}
+
+	return lexicon
+}
+
+// emojiSentiment - Sentiment for an emoji key, guild overrides win over the lexicon
+func emojiSentiment(overrides map[string]string, key string) string {
+	if sentiment, ok := overrides[key]; ok {
+		return sentiment
+	}
+	if sentiment, ok := sentimentLexicon[emojiModifiers.Replace(key)]; ok {
+		return sentiment
+	}
+
+	return sentimentNeutral
+}
+
+// moodCounts - Reactions split by sentiment
+type moodCounts struct {
+	Positive int64
+	Negative int64
+	Neutral  int64
+}
+
+func (m moodCounts) total() int64 {
+	return m.Positive + m.Negative + m.Neutral
+}
+
+func (m *moodCounts) add(sentiment string, count int64) {
+	switch sentiment {
+	case sentimentPositive:
+		m.Positive += count
+	case sentimentNegative:
+		m.Negative += count
+	default:
+		m.Neutral += count
+	}
+}
+
+// percents - Share of each sentiment, rounded
+func (m moodCounts) percents() (int64, int64, int64) {
+	total := m.total()
+	if total == 0 {
+		return 0, 0, 0
+	}
+
+	return m.Positive * 100 / total, m.Negative * 100 / total, m.Neutral * 100 / total
+}
+
+// bar - Fixed width row of coloured squares, largest remainder so it always fills the width
+func (m moodCounts) bar() string {
+	total := m.total()
+	if total == 0 {
+		return strings.Repeat("▫️", moodBarWidth)
+	}
+
+	counts := []int64{m.Positive, m.Negative, m.Neutral}
+	blocks := []string{"🟩", "🟥", "⬜"}
+	widths := make([]int64, len(counts))
+	used := int64(0)
+	for idx, count := range counts {
+		widths[idx] = count * moodBarWidth / total
+		used += widths[idx]
+	}
+	for used < moodBarWidth {
+		best := 0
+		for idx, count := range counts {
+			if count*moodBarWidth-widths[idx]*total > counts[best]*moodBarWidth-widths[best]*total {
+				best = idx
+			}
+		}
+		widths[best]++
+		used++
+	}
+
+	var sb strings.Builder
+	for idx, width := range widths {
+		sb.WriteString(strings.Repeat(blocks[idx], int(width)))
+	}
+
+	return sb.String()
+}
+
+// showChannelMood - Positive, negative and neutral reactions in a channel over time
+func showChannelMood(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	channelID := i.ChannelID
+	if opt, ok := optionMap["channel"]; ok {
+		channelID = opt.ChannelValue(nil).ID
+	}
+
+	bucketDays := 1
+	if opt, ok := optionMap["interval"]; ok && opt.StringValue() == "week" {
+		bucketDays = 7
+	}
+
+	buckets := 14
+	if bucketDays == 7 {
+		buckets = 12
+	}
+	if opt, ok := optionMap["periods"]; ok {
+		buckets = int(opt.IntValue())
+	}
+
+	timelines, err := b.Db.GetEmojiTimelinesForChannel(i.GuildID, channelID, bucketDays, buckets)
+	if err != nil {
+		return fmt.Errorf("getting channel emoji timelines: %w", err)
+	}
+
+	overrides, err := b.Db.GetEmojiSentiments(i.GuildID)
+	if err != nil {
+		return fmt.Errorf("getting emoji sentiments: %w", err)
+	}
+
+	overall := moodCounts{}
+	periods := make([]moodCounts, buckets)
+	topEmojis := map[string][]string{}
+	topCounts := map[string]int64{}
+	for key, timeline := range timelines {
+		sentiment := emojiSentiment(overrides, key)
+		total := int64(0)
+		for idx, count := range timeline.Counts {
+			periods[idx].add(sentiment, count)
+			total += count
+		}
+		overall.add(sentiment, total)
+		topCounts[key] = total
+		topEmojis[sentiment] = append(topEmojis[sentiment], key)
+	}
+
+	if overall.total() == 0 {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         fmt.Sprintf("No reactions in <#%s> for that period", channelID),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	// Label each bucket with the date it starts on, newest first
+	lines := []string{}
+	now := time.Now().UTC()
+	for idx := buckets - 1; idx >= 0; idx-- {
+		label := now.AddDate(0, 0, -(buckets-idx)*bucketDays+1).Format("01-02")
+		if periods[idx].total() == 0 {
+			lines = append(lines, fmt.Sprintf("`%s` %s no reactions", label, periods[idx].bar()))
+			continue
+		}
+
+		positive, negative, neutral := periods[idx].percents()
+		lines = append(lines, fmt.Sprintf("`%s` %s %d%% / %d%% / %d%%", label, periods[idx].bar(), positive, negative, neutral))
+	}
+
+	names := make(map[string]string, len(timelines))
+	for key, timeline := range timelines {
+		names[key] = timeline.EmojiName
+	}
+	topLine := func(sentiment string) string {
+		keys := topEmojis[sentiment]
+		sort.Slice(keys, func(a, b int) bool {
+			return topCounts[keys[a]] > topCounts[keys[b]]
+		})
+		if len(keys) > 5 {
+			keys = keys[:5]
+		}
+		if len(keys) == 0 {
+			return "None"
+		}
+
+		return formatEmojiKeys(keys, names)
+	}
+
+	interval := "Daily"
+	if bucketDays == 7 {
+		interval = "Weekly"
+	}
+
+	positive, negative, neutral := overall.percents()
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Mood in #%s", channelName(channelID)),
+		Description: fmt.Sprintf("%s\n🟩 Positive %d%% (%d)  🟥 Negative %d%% (%d)  ⬜ Neutral %d%% (%d)",
+			overall.bar(), positive, overall.Positive, negative, overall.Negative, neutral, overall.Neutral),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: interval + " (positive / negative / neutral)", Value: truncate(strings.Join(lines, "\n"), embedFieldValueLimit)},
+			{Name: "Top positive", Value: topLine(sentimentPositive), Inline: true},
+			{Name: "Top negative", Value: topLine(sentimentNegative), Inline: true},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Custom emojis are neutral unless set with /emoji-sentiment"},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// handleEmojiSentiment - Show or override an emoji's sentiment for this guild
+func handleEmojiSentiment(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	value := optionMap["emoji"].StringValue()
+	key := parseEmojiKey(i.GuildID, value)
+	display := value
+	if m := customEmojiRegex.FindStringSubmatch(strings.TrimSpace(value)); m == nil && key != value {
+		display = formatEmoji(strings.Trim(strings.TrimSpace(value), ":"), key)
+	}
+
+	opt, ok := optionMap["sentiment"]
+	if !ok {
+		overrides, err := b.Db.GetEmojiSentiments(i.GuildID)
+		if err != nil {
+			return fmt.Errorf("getting emoji sentiments: %w", err)
+		}
+
+		source := "default"
+		if _, ok := overrides[key]; ok {
+			source = "set for this server"
+		}
+
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         fmt.Sprintf("%s is %s (%s)", display, emojiSentiment(overrides, key), source),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	sentiment := opt.StringValue()
+	if sentiment == "default" {
+		err := b.Db.RemoveEmojiSentiment(i.GuildID, key)
+		if err != nil {
+			return fmt.Errorf("removing emoji sentiment: %w", err)
+		}
+
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         fmt.Sprintf(":white_check_mark: %s is back to its default, %s", display, emojiSentiment(nil, key)),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	err := b.Db.SetEmojiSentiment(i.GuildID, key, sentiment)
+	if err != nil {
+		return fmt.Errorf("setting emoji sentiment: %w", err)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf(":white_check_mark: %s is now %s in this server", display, sentiment),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
@@ -0,0 +1,296 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+const (
+	// spamEvidenceLimit - Reactions listed in a mod-log alert
+	spamEvidenceLimit = 10
+
+	// spamPruneInterval - How often idle users' windows are cleared out
+	spamPruneInterval = time.Minute
+)
+
+type spamReaction struct {
+	ChannelID string
+	MessageID string
+	Emoji     discordgo.Emoji
+	At        time.Time
+}
+
+type SpamGuards struct {
+	// settings map[GuildID]db.SpamGuard, loaded on first use
+	settings map[string]db.SpamGuard
+	// windows map[GuildID:UserID][]spamReaction, oldest first
+	windows   map[string][]spamReaction
+	lastPrune time.Time
+	mutex     sync.Mutex
+}
+
+var spamGuards = &SpamGuards{
+	settings: make(map[string]db.SpamGuard),
+	windows:  make(map[string][]spamReaction),
+}
+
+// settingsFor - Cached settings for a guild. Caller holds the mutex
+func (g *SpamGuards) settingsFor(guildID string) (db.SpamGuard, error) {
+	if guard, ok := g.settings[guildID]; ok {
+		return guard, nil
+	}
+
+	guard, err := b.Db.GetSpamGuard(guildID)
+	if err != nil {
+		return guard, err
+	}
+	g.settings[guildID] = guard
+
+	return guard, nil
+}
+
+// set - Save and cache new settings for a guild
+func (g *SpamGuards) set(guildID string, guard db.SpamGuard) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	err := b.Db.SetSpamGuard(guildID, guard)
+	if err != nil {
+		return err
+	}
+	g.settings[guildID] = guard
+
+	return nil
+}
+
+// observe - Add a reaction to the user's sliding window. Returns the reason and evidence if it tripped a rule,
+// in which case the window is cleared so one burst raises one alert
+func (g *SpamGuards) observe(guildID string, userID string, reaction spamReaction) (string, []spamReaction, db.SpamGuard) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	guard, err := g.settingsFor(guildID)
+	if err != nil {
+		slog.Error("Failed to load spam guard", "err", err, "guild_id", guildID)
+		return "", nil, guard
+	}
+	if !guard.Enabled {
+		return "", nil, guard
+	}
+
+	if reaction.At.Sub(g.lastPrune) >= spamPruneInterval {
+		g.prune(reaction.At)
+	}
+
+	key := guildID + ":" + userID
+	window := append(trimSpamWindow(g.windows[key], reaction.At, guard), reaction)
+	g.windows[key] = window
+
+	reason := ""
+	evidence := window
+	if int64(len(window)) > guard.MaxReactions {
+		reason = fmt.Sprintf("%d reactions in %d seconds", len(window), guard.WindowSeconds)
+	} else {
+		// Same emoji across different messages
+		emoji := emojiKey(reaction.Emoji.Name, reaction.Emoji.ID)
+		messages := make(map[string]bool)
+		sameEmoji := []spamReaction{}
+		for _, r := range window {
+			if emojiKey(r.Emoji.Name, r.Emoji.ID) == emoji && !messages[r.MessageID] {
+				messages[r.MessageID] = true
+				sameEmoji = append(sameEmoji, r)
+			}
+		}
+		if int64(len(messages)) >= guard.SameEmojiMessages {
+			reason = fmt.Sprintf("%s on %d messages in %d seconds", formatEmoji(reaction.Emoji.Name, reaction.Emoji.ID), len(messages), guard.WindowSeconds)
+			evidence = sameEmoji
+		}
+	}
+
+	if reason == "" {
+		return "", nil, guard
+	}
+
+	delete(g.windows, key)
+	return reason, evidence, guard
+}
+
+// trimSpamWindow - Drop reactions older than the guild's window
+func trimSpamWindow(window []spamReaction, now time.Time, guard db.SpamGuard) []spamReaction {
+	cutoff := now.Add(-time.Duration(guard.WindowSeconds) * time.Second)
+	for len(window) > 0 && window[0].At.Before(cutoff) {
+		window = window[1:]
+	}
+
+	return window
+}
+
+// prune - Drop the windows of users who have stopped reacting. Caller holds the mutex
+func (g *SpamGuards) prune(now time.Time) {
+	for key, window := range g.windows {
+		guildID, _, _ := strings.Cut(key, ":")
+		if len(trimSpamWindow(window, now, g.settings[guildID])) == 0 {
+			delete(g.windows, key)
+		}
+	}
+	g.lastPrune = now
+}
+
+// spamAction - What the spam guard did to a user, from the scrub rule in effect afterwards
+func spamAction(rule db.Scrub, added bool, err error) string {
+	switch {
+	case err != nil:
+		return "Reactions removed, but scrubbing the user failed"
+	case added:
+		return fmt.Sprintf("Reactions removed and scrubbed until %s", discordTimestamp(rule.ExpiresAt, "t"))
+	}
+
+	existing := "until " + discordTimestamp(rule.ExpiresAt, "f")
+	if rule.ExpiresAt.IsZero() {
+		existing = "permanently"
+	}
+	if rule.AddedBy != "" {
+		existing += fmt.Sprintf(" by <@%s>", rule.AddedBy)
+	}
+
+	return "Reactions removed, already scrubbed " + existing
+}
+
+// checkReactionSpam - Run the spam guard for a new reaction, scrubbing the user and alerting mods if it trips.
+// Returns whether the user was scrubbed
+func checkReactionSpam(reaction *messageReactionAdd) bool {
+	reason, evidence, guard := spamGuards.observe(reaction.GuildID, reaction.UserID, spamReaction{
+		ChannelID: reaction.ChannelID,
+		MessageID: reaction.MessageID,
+		Emoji:     reaction.Emoji,
+		At:        time.Now(),
+	})
+	if reason == "" {
+		return false
+	}
+
+	until := time.Now().Add(time.Duration(guard.ScrubMinutes) * time.Minute)
+	rule, added, err := scrub.scrubTemporarily(reaction.GuildID, reaction.UserID, until, "Spam guard: "+reason)
+	if err != nil {
+		slog.Error("Failed to scrub reaction spammer", "err", err, "user_id", reaction.UserID)
+	}
+	slog.Info("Reaction spam detected", "guild_id", reaction.GuildID, "user_id", reaction.UserID, "reason", reason)
+
+	// Take the burst back off, the remove events clean up emoji_usage. The current reaction is left to the scrubber
+	for _, r := range evidence {
+		if r.MessageID == reaction.MessageID && r.Emoji.APIName() == reaction.Emoji.APIName() {
+			continue
+		}
+		err := b.DiscordSession.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.APIName(), reaction.UserID)
+		if err != nil {
+			slog.Error("Failed to remove spammed reaction", "err", err, "message_id", r.MessageID)
+		}
+	}
+
+	if guard.ModLogChannelID == "" {
+		return true
+	}
+
+	lines := []string{}
+	for idx, r := range evidence {
+		if idx >= spamEvidenceLimit {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(evidence)-idx))
+			break
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%s on [message](https://discord.com/channels/%s/%s/%s) in <#%s> %s",
+			formatEmoji(r.Emoji.Name, r.Emoji.ID), reaction.GuildID, r.ChannelID, r.MessageID, r.ChannelID, discordTimestamp(r.At, "T"),
+		))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Reaction spam detected",
+		Description: fmt.Sprintf("<@%s> (%s): %s", reaction.UserID, reaction.UserID, reason),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Evidence", Value: truncate(strings.Join(lines, "\n"), embedFieldValueLimit)},
+			{Name: "Action", Value: spamAction(rule, added, err)},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	_, err = b.DiscordSession.ChannelMessageSendComplex(guard.ModLogChannelID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		slog.Error("Failed to send spam alert", "err", err, "guild_id", reaction.GuildID, "channel_id", guard.ModLogChannelID)
+	}
+
+	return true
+}
+
+// handleSpamGuard - /spam-guard set and /spam-guard disable
+func handleSpamGuard(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return fmt.Errorf("no spam-guard subcommand")
+	}
+
+	subcommand := data.Options[0]
+
+	// Access options in the order provided by the user.
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, opt := range subcommand.Options {
+		optionMap[opt.Name] = opt
+	}
+
+	guard, err := b.Db.GetSpamGuard(i.GuildID)
+	if err != nil {
+		return fmt.Errorf("getting spam guard: %w", err)
+	}
+
+	msg := ":white_check_mark: Spam guard disabled"
+	guard.Enabled = subcommand.Name != "disable"
+	if guard.Enabled {
+		if opt, ok := optionMap["max-reactions"]; ok {
+			guard.MaxReactions = opt.IntValue()
+		}
+		if opt, ok := optionMap["window-seconds"]; ok {
+			guard.WindowSeconds = opt.IntValue()
+		}
+		if opt, ok := optionMap["same-emoji-messages"]; ok {
+			guard.SameEmojiMessages = opt.IntValue()
+		}
+		if opt, ok := optionMap["scrub-minutes"]; ok {
+			guard.ScrubMinutes = opt.IntValue()
+		}
+		if opt, ok := optionMap["mod-log"]; ok {
+			guard.ModLogChannelID = opt.ChannelValue(nil).ID
+		}
+		if opt, ok := optionMap["clear-mod-log"]; ok && opt.BoolValue() {
+			guard.ModLogChannelID = ""
+		}
+
+		msg = fmt.Sprintf(
+			":white_check_mark: Spam guard on: more than %d reactions or the same emoji on %d messages within %d seconds scrubs the user for %d minutes",
+			guard.MaxReactions, guard.SameEmojiMessages, guard.WindowSeconds, guard.ScrubMinutes,
+		)
+		if guard.ModLogChannelID != "" {
+			msg += fmt.Sprintf(", alerts go to <#%s>", guard.ModLogChannelID)
+		}
+	}
+
+	err = spamGuards.set(i.GuildID, guard)
+	if err != nil {
+		return fmt.Errorf("setting spam guard: %w", err)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+// starboardEmbedColor - Gold
+const starboardEmbedColor = 0xffac33
+
+type Starboards struct {
+	// configs map[GuildID]db.Starboard
+	configs map[string]db.Starboard
+	mutex   sync.RWMutex
+
+	// postMutex - Serialises posting so reactions landing together can't both create a post
+	postMutex sync.Mutex
+}
+
+// starboards - Created up front as reactions can arrive before the configs are loaded
+var starboards = &Starboards{configs: make(map[string]db.Starboard)}
+
+// initStarboard - Loads the starboard configs from the DB
+func initStarboard() error {
+	allStarboards, err := b.Db.GetAllStarboards()
+	if err != nil {
+		return err
+	}
+
+	for _, starboard := range allStarboards {
+		starboards.set(starboard)
+	}
+
+	return nil
+}
+
+// get - Starboard config for a guild
+func (s *Starboards) get(guildID string) (db.Starboard, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	starboard, ok := s.configs[guildID]
+	return starboard, ok
+}
+
+// set - Cache a guild's starboard config
+func (s *Starboards) set(starboard db.Starboard) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.configs[starboard.GuildID] = starboard
+}
+
+// remove - Forget a guild's starboard config
+func (s *Starboards) remove(guildID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.configs, guildID)
+}
+
+// evaluate - Post, edit or remove the starboard entry for a message after its reactions changed
+func (s *Starboards) evaluate(guildID, channelID, messageID, emojiKey string) {
+	starboard, ok := s.get(guildID)
+	if !ok || starboard.Emoji != emojiKey || channelID == starboard.ChannelID || slices.Contains(starboard.IgnoredChannels, channelID) {
+		return
+	}
+
+	s.postMutex.Lock()
+	defer s.postMutex.Unlock()
+
+	count, err := b.Db.CountReactionsForMessage(guildID, messageID, emojiKey)
+	if err != nil {
+		slog.Error("Failed to count starboard reactions", "err", err, "message_id", messageID)
+		return
+	}
+
+	entry, posted, err := b.Db.GetStarboardEntry(guildID, messageID)
+	if err != nil {
+		slog.Error("Failed to get starboard entry", "err", err, "message_id", messageID)
+		return
+	}
+
+	if count < starboard.Threshold {
+		if posted && starboard.RemoveBelow {
+			s.unpost(entry)
+			return
+		}
+		if !posted {
+			return
+		}
+	}
+
+	if posted && entry.Count == count {
+		return
+	}
+
+	message, err := b.DiscordSession.ChannelMessage(channelID, messageID)
+	if err != nil {
+		slog.Error("Failed to get starred message", "err", err, "channel_id", channelID, "message_id", messageID)
+		return
+	}
+
+	content := fmt.Sprintf("%s **%d** in <#%s>", starboard.EmojiDisplay, count, channelID)
+	embed := buildStarboardEmbed(guildID, message)
+
+	if posted {
+		_, err = b.DiscordSession.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:              entry.StarboardMessageID,
+			Channel:         starboard.ChannelID,
+			Content:         &content,
+			Embeds:          &[]*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+		if err != nil {
+			slog.Error("Failed to edit starboard post", "err", err, "message_id", messageID)
+			return
+		}
+	} else {
+		post, err := b.DiscordSession.ChannelMessageSendComplex(starboard.ChannelID, &discordgo.MessageSend{
+			Content:         content,
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+		if err != nil {
+			slog.Error("Failed to post to starboard", "err", err, "guild_id", guildID, "channel_id", starboard.ChannelID)
+			return
+		}
+		entry = db.StarboardEntry{GuildID: guildID, ChannelID: channelID, MessageID: messageID, StarboardMessageID: post.ID}
+	}
+
+	entry.Count = count
+	err = b.Db.SetStarboardEntry(entry)
+	if err != nil {
+		slog.Error("Failed to save starboard entry", "err", err, "message_id", messageID)
+	}
+}
+
+// unpost - Delete a starboard post that fell below the threshold
+func (s *Starboards) unpost(entry db.StarboardEntry) {
+	starboard, _ := s.get(entry.GuildID)
+	err := b.DiscordSession.ChannelMessageDelete(starboard.ChannelID, entry.StarboardMessageID)
+	if err != nil {
+		slog.Error("Failed to delete starboard post", "err", err, "message_id", entry.MessageID)
+	}
+
+	err = b.Db.RemoveStarboardEntry(entry.GuildID, entry.MessageID)
+	if err != nil {
+		slog.Error("Failed to remove starboard entry", "err", err, "message_id", entry.MessageID)
+	}
+}
+
+// buildStarboardEmbed - Quote of the starred message with a jump link
+func buildStarboardEmbed(guildID string, message *discordgo.Message) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Description: truncate(message.Content, embedDescriptionLimit),
+		Color:       starboardEmbedColor,
+		Timestamp:   message.Timestamp.Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Source", Value: fmt.Sprintf("[Jump to message](https://discord.com/channels/%s/%s/%s)", guildID, message.ChannelID, message.ID)},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: message.ID},
+	}
+
+	if message.Author != nil {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: message.Author.Username, IconURL: message.Author.AvatarURL("")}
+	}
+
+	for _, attachment := range message.Attachments {
+		if attachment.Width > 0 {
+			embed.Image = &discordgo.MessageEmbedImage{URL: attachment.URL}
+			break
+		}
+	}
+
+	return embed
+}
+
+// handleStarboard - /starboard set, /starboard ignore and /starboard disable
+func handleStarboard(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return fmt.Errorf("no starboard subcommand")
+	}
+
+	subcommand := data.Options[0]
+
+	// Access options in the order provided by the user.
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, opt := range subcommand.Options {
+		optionMap[opt.Name] = opt
+	}
+
+	msg := ":white_check_mark:"
+	switch subcommand.Name {
+	case "disable":
+		err := b.Db.RemoveStarboard(i.GuildID)
+		if err != nil {
+			return fmt.Errorf("removing starboard: %w", err)
+		}
+		starboards.remove(i.GuildID)
+	case "ignore":
+		starboard, ok := starboards.get(i.GuildID)
+		if !ok {
+			msg = "Set up the starboard with `/starboard set` first"
+			break
+		}
+
+		channelID := optionMap["channel"].ChannelValue(nil).ID
+		ignored := slices.Clone(starboard.IgnoredChannels)
+		if idx := slices.Index(ignored, channelID); idx >= 0 {
+			ignored = slices.Delete(ignored, idx, idx+1)
+			msg = fmt.Sprintf(":white_check_mark: <#%s> can be starred again", channelID)
+		} else {
+			ignored = append(ignored, channelID)
+			msg = fmt.Sprintf(":white_check_mark: Messages in <#%s> won't be starred", channelID)
+		}
+
+		err := b.Db.SetStarboardIgnoredChannels(i.GuildID, ignored)
+		if err != nil {
+			return fmt.Errorf("setting starboard ignored channels: %w", err)
+		}
+		starboard.IgnoredChannels = ignored
+		starboards.set(starboard)
+	default:
+		starboard, _ := starboards.get(i.GuildID)
+		starboard.GuildID = i.GuildID
+		starboard.ChannelID = optionMap["channel"].ChannelValue(nil).ID
+		starboard.Emoji = "⭐"
+		starboard.Threshold = 3
+		starboard.RemoveBelow = false
+
+		if opt, ok := optionMap["emoji"]; ok {
+			starboard.Emoji = parseEmojiKey(i.GuildID, opt.StringValue())
+		}
+		if opt, ok := optionMap["threshold"]; ok {
+			starboard.Threshold = opt.IntValue()
+		}
+		if opt, ok := optionMap["remove-below"]; ok {
+			starboard.RemoveBelow = opt.BoolValue()
+		}
+
+		starboard.EmojiDisplay = starboard.Emoji
+		if emoji, err := s.State.Emoji(i.GuildID, starboard.Emoji); err == nil {
+			starboard.EmojiDisplay = emoji.MessageFormat()
+		}
+
+		err := b.Db.SetStarboard(starboard.GuildID, starboard.ChannelID, starboard.Emoji, starboard.EmojiDisplay, starboard.Threshold, starboard.RemoveBelow)
+		if err != nil {
+			return fmt.Errorf("setting starboard: %w", err)
+		}
+		starboards.set(starboard)
+
+		msg = fmt.Sprintf(":white_check_mark: Messages with %d %s will be posted in <#%s>", starboard.Threshold, starboard.EmojiDisplay, starboard.ChannelID)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
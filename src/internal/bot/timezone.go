@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// guildLocation - Configured timezone for a guild, UTC until one is set
+func guildLocation(guildID string) *time.Location {
+	timezone, err := b.Db.GetGuildTimezone(guildID)
+	if err != nil {
+		slog.Error("Error getting guild timezone", "err", err, "guild_id", guildID)
+		return time.UTC
+	}
+	if timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		slog.Error("Invalid guild timezone", "err", err, "guild_id", guildID, "timezone", timezone)
+		return time.UTC
+	}
+
+	return location
+}
+
+// handleTimezone - Show or set the guild's timezone
+func handleTimezone(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		location := guildLocation(i.GuildID)
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         fmt.Sprintf("This server uses %s, it's %s there now", location, time.Now().In(location).Format("15:04 Mon")),
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	timezone := options[0].StringValue()
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || timezone == "Local" {
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "Timezone must be an IANA name, e.g. Pacific/Auckland or Europe/London",
+				Flags:           discordgo.MessageFlagsEphemeral,
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	err = b.Db.SetGuildTimezone(i.GuildID, location.String())
+	if err != nil {
+		return fmt.Errorf("setting guild timezone: %w", err)
+	}
+	achievements.forgetLocation(i.GuildID)
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf(":white_check_mark: Timezone set to %s, it's %s there now", location, time.Now().In(location).Format("15:04 Mon")),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
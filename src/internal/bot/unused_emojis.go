@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/idanoo/GoDiscMoji/internal/db"
+)
+
+// maxMessageLength - Discord's limit for message content
+const maxMessageLength = 2000
+
+// showUnusedEmojis - List custom emojis with few or no uses in a period
+func showUnusedEmojis(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	days := int64(30)
+	if opt, ok := optionMap["days"]; ok {
+		days = opt.IntValue()
+	}
+
+	maxUses := int64(0)
+	if opt, ok := optionMap["max-uses"]; ok {
+		maxUses = opt.IntValue()
+	}
+
+	emojis, err := s.GuildEmojis(i.GuildID)
+	if err != nil {
+		return fmt.Errorf("getting guild emojis: %w", err)
+	}
+
+	activity, err := b.Db.GetCustomEmojiActivityForGuild(i.GuildID, days)
+	if err != nil {
+		return fmt.Errorf("getting custom emoji activity: %w", err)
+	}
+
+	// Join the guild catalog against recorded usage
+	unused := make([]*discordgo.Emoji, 0)
+	for _, emoji := range emojis {
+		if activity[emoji.ID].Count <= maxUses {
+			unused = append(unused, emoji)
+		}
+	}
+
+	// Never used first, then least recently used
+	sort.SliceStable(unused, func(x, y int) bool {
+		return activity[unused[x].ID].LastSeen.Before(activity[unused[y].ID].LastSeen)
+	})
+
+	msg := fmt.Sprintf("Custom emojis with %d or fewer uses in the last %d days (%d of %d):\n", maxUses, days, len(unused), len(emojis))
+	if len(unused) == 0 {
+		msg = fmt.Sprintf("Every custom emoji has more than %d uses in the last %d days", maxUses, days)
+	}
+	for idx, emoji := range unused {
+		line := unusedEmojiLine(emoji, activity[emoji.ID])
+		more := fmt.Sprintf("...and %d more\n", len(unused)-idx)
+		if len(msg)+len(line)+len(more) > maxMessageLength {
+			msg += more
+			break
+		}
+		msg += line
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         msg,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// unusedEmojiLine - One row of the unused emoji report
+func unusedEmojiLine(emoji *discordgo.Emoji, activity db.EmojiActivity) string {
+	uploaded, err := discordgo.SnowflakeTimestamp(emoji.ID)
+	if err != nil {
+		slog.Error("Error parsing emoji ID", "err", err, "emoji_id", emoji.ID)
+	}
+
+	return fmt.Sprintf(
+		"%s `:%s:` %d uses, last used %s, uploaded %s\n",
+		emoji.MessageFormat(),
+		emoji.Name,
+		activity.Count,
+		discordTimestamp(activity.LastSeen, "R"),
+		discordTimestamp(uploaded, "R"),
+	)
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// showUserProfileContext - "Emoji profile" user context-menu command, how a member reacts and is reacted to
+func showUserProfileContext(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	embed, err := buildUserProfileEmbed(i.GuildID, contextTargetUser(i))
+	if err != nil {
+		return fmt.Errorf("building user profile: %w", err)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// buildUserProfileEmbed - Signature emoji, mood, reactions received, streak and achievements for a user
+func buildUserProfileEmbed(guildID string, user *discordgo.User) (*discordgo.MessageEmbed, error) {
+	embed := &discordgo.MessageEmbed{
+		Title: "Emoji profile",
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    user.Username,
+			IconURL: user.AvatarURL(""),
+		},
+	}
+
+	all, err := b.Db.GetAllEmojisForUser(guildID, user.ID)
+	if err != nil {
+		return embed, err
+	}
+
+	received, err := b.Db.CountReceivedForGuildUser(guildID, user.ID)
+	if err != nil {
+		return embed, err
+	}
+
+	if len(all) == 0 && received == 0 {
+		embed.Description = fmt.Sprintf("<@%s> hasn't reacted or been reacted to yet", user.ID)
+		return embed, nil
+	}
+
+	overrides, err := b.Db.GetEmojiSentiments(guildID)
+	if err != nil {
+		slog.Error("Error getting emoji sentiments", "err", err)
+	}
+
+	// Tally mood, the streak and when they started reacting
+	mood := moodCounts{}
+	hourly := make(map[time.Time]int64)
+	names := make(map[string]string)
+	counts := make(map[string]int64)
+	first := time.Time{}
+	for _, usage := range all {
+		key := emojiKey(usage.EmojiName, usage.EmojiID)
+		mood.add(emojiSentiment(overrides, key), 1)
+		hourly[usage.Timestamp]++
+		names[key] = usage.EmojiName
+		counts[key]++
+		if first.IsZero() || usage.Timestamp.Before(first) {
+			first = usage.Timestamp
+		}
+	}
+
+	signature := "None"
+	if len(counts) > 0 {
+		keys := make([]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(x, y int) bool {
+			if counts[keys[x]] == counts[keys[y]] {
+				return keys[x] < keys[y]
+			}
+			return counts[keys[x]] > counts[keys[y]]
+		})
+		signature = fmt.Sprintf("%s (%d%% of reactions)", formatEmoji(names[keys[0]], keys[0]), counts[keys[0]]*100/int64(len(all)))
+	}
+
+	moodLine := "None"
+	if mood.total() > 0 {
+		positive, negative, neutral := mood.percents()
+		moodLine = fmt.Sprintf("%s\n%d%% / %d%% / %d%%", mood.bar(), positive, negative, neutral)
+	}
+
+	receivedEmojis, err := b.Db.GetTopEmojisForGuildReceiver(guildID, "", 0, user.ID, 3)
+	if err != nil {
+		slog.Error("Error getting top emojis for guild receiver", "err", err)
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range receivedEmojis {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	receivedList := []string{fmt.Sprintf("%d total", received)}
+	for _, v := range keys {
+		receivedList = append(receivedList, fmt.Sprintf("%s %d", formatEmoji(receivedEmojis[v].EmojiName, receivedEmojis[v].EmojiID), receivedEmojis[v].Count))
+	}
+
+	ratio := "n/a"
+	if received > 0 {
+		ratio = fmt.Sprintf("%.2f given per received", float64(len(all))/float64(received))
+	}
+
+	awarded, err := b.Db.GetAchievementsForUser(guildID, user.ID)
+	if err != nil {
+		slog.Error("Error getting achievements", "err", err)
+	}
+	trophies := []string{}
+	for _, achievement := range awarded {
+		trophies = append(trophies, ":trophy: "+achievementLabel(achievement.Key))
+	}
+
+	embed.Description = fmt.Sprintf("<@%s>", user.ID)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Signature emoji", Value: signature, Inline: true},
+		{Name: "Reacting since", Value: discordTimestamp(first, "D"), Inline: true},
+		{Name: "Streak", Value: fmt.Sprintf("%d days", reactionStreak(hourly, time.Now(), guildLocation(guildID))), Inline: true},
+		{Name: "Mood (positive / negative / neutral)", Value: moodLine, Inline: true},
+		{Name: "Reactions received", Value: strings.Join(receivedList, "\n"), Inline: true},
+		{Name: "Given vs received", Value: ratio, Inline: true},
+		{Name: "Achievements", Value: joinRows(trophies, embedFieldValueLimit)},
+	}
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+
+	return embed, nil
+}
@@ -0,0 +1,181 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// showUserStats - Show an emoji profile for a user
+func showUserStats(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	// Access options in the order provided by the user.
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	user := &discordgo.User{}
+	if opt, ok := optionMap["user"]; ok {
+		user = opt.UserValue(s)
+	} else {
+		slog.Error("Invalid user option provided")
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:         "No user specified",
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			},
+		})
+	}
+
+	return respondUserStats(s, i, user)
+}
+
+// showUserStatsContext - "Emoji stats" user context-menu version of /user-stats
+func showUserStatsContext(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return respondUserStats(s, i, contextTargetUser(i))
+}
+
+// contextTargetUser - User a user context-menu command was run on
+func contextTargetUser(i *discordgo.InteractionCreate) *discordgo.User {
+	data := i.ApplicationCommandData()
+	user := &discordgo.User{ID: data.TargetID}
+	if data.Resolved != nil {
+		if u, ok := data.Resolved.Users[data.TargetID]; ok {
+			user = u
+		}
+	}
+
+	return user
+}
+
+// respondUserStats - Build and send the profile embed
+func respondUserStats(s *discordgo.Session, i *discordgo.InteractionCreate, user *discordgo.User) error {
+	embed, err := buildUserStatsEmbed(i.GuildID, user)
+	if err != nil {
+		return fmt.Errorf("building user stats: %w", err)
+	}
+
+	return respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// buildUserStatsEmbed - Summarise a user's emoji usage in a guild
+func buildUserStatsEmbed(guildID string, user *discordgo.User) (*discordgo.MessageEmbed, error) {
+	embed := &discordgo.MessageEmbed{
+		Title: "Emoji stats",
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    user.Username,
+			IconURL: user.AvatarURL(""),
+		},
+	}
+
+	all, err := b.Db.GetAllEmojisForUser(guildID, user.ID)
+	if err != nil {
+		return embed, err
+	}
+
+	if len(all) == 0 {
+		embed.Description = fmt.Sprintf("<@%s> hasn't reacted with any emojis yet", user.ID)
+		return embed, nil
+	}
+
+	rank, err := b.Db.GetUserRankForGuild(guildID, user.ID)
+	if err != nil {
+		slog.Error("Error getting user rank", "err", err)
+	}
+
+	topEmojis, err := b.Db.GetTopEmojisForGuildUser(guildID, "", 0, user.ID, 5)
+	if err != nil {
+		slog.Error("Error getting top emojis for guild user", "err", err)
+	}
+
+	recent, err := b.Db.GetRecentEmojisForUser(guildID, user.ID, 24*7)
+	if err != nil {
+		slog.Error("Error getting recent emojis for user", "err", err)
+	}
+
+	// Tally channels, hours in the guild's timezone and distinct emojis
+	location := guildLocation(guildID)
+	channels := make(map[string]int64)
+	hours := make(map[int]int64)
+	distinct := make(map[string]bool)
+	for _, usage := range all {
+		channels[usage.ChannelID]++
+		hours[usage.Timestamp.In(location).Hour()]++
+		distinct[emojiKey(usage.EmojiName, usage.EmojiID)] = true
+	}
+
+	// Sort keys
+	keys := make([]int, 0)
+	for k := range topEmojis {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	emojis := []string{}
+	for _, v := range keys {
+		emojis = append(emojis, fmt.Sprintf("%s %d", formatEmoji(topEmojis[v].EmojiName, topEmojis[v].EmojiID), topEmojis[v].Count))
+	}
+
+	channelIDs := make([]string, 0, len(channels))
+	for k := range channels {
+		channelIDs = append(channelIDs, k)
+	}
+	sort.Slice(channelIDs, func(x, y int) bool { return channels[channelIDs[x]] > channels[channelIDs[y]] })
+	favourites := []string{}
+	for idx, channelID := range channelIDs {
+		if idx >= 3 {
+			break
+		}
+		favourites = append(favourites, fmt.Sprintf("<#%s>: %d", channelID, channels[channelID]))
+	}
+
+	hourKeys := make([]int, 0, len(hours))
+	for k := range hours {
+		hourKeys = append(hourKeys, k)
+	}
+	sort.Slice(hourKeys, func(x, y int) bool { return hours[hourKeys[x]] > hours[hourKeys[y]] })
+	activeHours := []string{}
+	for idx, hour := range hourKeys {
+		if idx >= 3 {
+			break
+		}
+		activeHours = append(activeHours, fmt.Sprintf("%02d:00 (%d)", hour, hours[hour]))
+	}
+
+	recentList := []string{}
+	for idx, usage := range recent {
+		if idx >= 5 {
+			break
+		}
+		recentList = append(recentList, fmt.Sprintf("%s %s", formatEmoji(usage.EmojiName, usage.EmojiID), discordTimestamp(usage.Timestamp, "R")))
+	}
+	if len(recentList) == 0 {
+		recentList = append(recentList, "Nothing in the last 7 days")
+	}
+
+	diversity := float64(len(distinct)) / float64(len(all)) * 100
+	embed.Description = fmt.Sprintf("<@%s>", user.ID)
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Total reactions", Value: fmt.Sprintf("%d", len(all)), Inline: true},
+		{Name: "Rank", Value: fmt.Sprintf("#%d", rank), Inline: true},
+		{Name: "Diversity", Value: fmt.Sprintf("%d distinct (%.1f%%)", len(distinct), diversity), Inline: true},
+		{Name: "Top emojis", Value: strings.Join(emojis, "\n"), Inline: true},
+		{Name: "Favourite channels", Value: strings.Join(favourites, "\n"), Inline: true},
+		{Name: fmt.Sprintf("Active hours (%s)", location), Value: strings.Join(activeHours, "\n"), Inline: true},
+		{Name: fmt.Sprintf("Recent activity (%d in 7 days)", len(recent)), Value: strings.Join(recentList, "\n")},
+	}
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+
+	return embed, nil
+}
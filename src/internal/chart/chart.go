@@ -0,0 +1,178 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+type Style string
+
+const (
+	StyleLine Style = "line"
+	StyleBar  Style = "bar"
+)
+
+const (
+	width        = 800
+	height       = 400
+	marginLeft   = 60
+	marginRight  = 20
+	marginTop    = 20
+	marginBottom = 40
+	gridLines    = 4
+)
+
+var (
+	Background = color.RGBA{0x2b, 0x2d, 0x31, 0xff}
+	Foreground = color.RGBA{0xdb, 0xde, 0xe1, 0xff}
+	Grid       = color.RGBA{0x3f, 0x41, 0x47, 0xff}
+
+	// Palette - Series colours, these line up with 🟥 🟦 🟩 🟨 🟪 so a text legend can be sent alongside
+	Palette = []color.RGBA{
+		{0xdd, 0x2e, 0x44, 0xff},
+		{0x55, 0xac, 0xee, 0xff},
+		{0x78, 0xb1, 0x59, 0xff},
+		{0xfd, 0xcb, 0x58, 0xff},
+		{0xaa, 0x8e, 0xd6, 0xff},
+	}
+	PaletteLegend = []string{"🟥", "🟦", "🟩", "🟨", "🟪"}
+)
+
+type Series struct {
+	Values []int64
+	Color  color.RGBA
+}
+
+// Render - Draw series over the labelled buckets and encode as PNG
+func Render(w io.Writer, labels []string, series []Series, style Style) error {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{Background}, image.Point{}, draw.Src)
+
+	plotW := width - marginLeft - marginRight
+	plotH := height - marginTop - marginBottom
+	buckets := len(labels)
+	if buckets == 0 {
+		return png.Encode(w, img)
+	}
+
+	// Scale the y axis to a value divisible by the grid
+	max := int64(0)
+	for _, s := range series {
+		for _, v := range s.Values {
+			if v > max {
+				max = v
+			}
+		}
+	}
+	max = ((max + gridLines) / gridLines) * gridLines
+
+	yFor := func(v int64) int {
+		return marginTop + plotH - int(float64(v)/float64(max)*float64(plotH))
+	}
+
+	// Grid and y labels
+	for g := 0; g <= gridLines; g++ {
+		v := max * int64(g) / gridLines
+		y := yFor(v)
+		fillRect(img, marginLeft, y, plotW, 1, Grid)
+		label := fmt.Sprintf("%d", v)
+		drawText(img, marginLeft-8-textWidth(label), y-glyphHeight/2, label, Foreground)
+	}
+
+	// X labels, skipping some when they would overlap
+	slot := float64(plotW) / float64(buckets)
+	step := 1
+	for float64(step)*slot < float64(textWidth(labels[0])+10) {
+		step++
+	}
+	for idx, label := range labels {
+		if idx%step != 0 {
+			continue
+		}
+		x := marginLeft + int(slot*float64(idx)+slot/2) - textWidth(label)/2
+		drawText(img, x, height-marginBottom+12, label, Foreground)
+	}
+
+	switch style {
+	case StyleBar:
+		barW := int(slot / float64(len(series)+1))
+		if barW < 1 {
+			barW = 1
+		}
+		for sIdx, s := range series {
+			for idx, v := range s.Values {
+				x := marginLeft + int(slot*float64(idx)) + barW/2 + sIdx*barW
+				y := yFor(v)
+				fillRect(img, x, y, barW, marginTop+plotH-y, s.Color)
+			}
+		}
+	default:
+		for _, s := range series {
+			for idx := range s.Values {
+				x := marginLeft + int(slot*float64(idx)+slot/2)
+				y := yFor(s.Values[idx])
+				fillRect(img, x-2, y-2, 5, 5, s.Color)
+				if idx > 0 {
+					px := marginLeft + int(slot*float64(idx-1)+slot/2)
+					py := yFor(s.Values[idx-1])
+					drawLine(img, px, py, x, y, s.Color)
+				}
+			}
+		}
+	}
+
+	// Axes
+	fillRect(img, marginLeft, marginTop, 1, plotH, Foreground)
+	fillRect(img, marginLeft, marginTop+plotH, plotW, 1, Foreground)
+
+	return png.Encode(w, img)
+}
+
+// fillRect - Fill a rectangle, clipped to the image
+func fillRect(img *image.RGBA, x int, y int, w int, h int, c color.Color) {
+	draw.Draw(img, image.Rect(x, y, x+w, y+h).Intersect(img.Bounds()), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// drawLine - Bresenham line, 2px thick
+func drawLine(img *image.RGBA, x0 int, y0 int, x1 int, y1 int, c color.Color) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	e := dx + dy
+	for {
+		fillRect(img, x0, y0, 2, 2, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs - Absolute value of an int
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,72 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+// glyphs - Tiny 3x5 bitmap font, enough for axis labels and weekday names
+var glyphs = map[rune][5]string{
+	'0': {"111", "101", "101", "101", "111"},
+	'1': {"010", "110", "010", "010", "111"},
+	'2': {"111", "001", "111", "100", "111"},
+	'3': {"111", "001", "111", "001", "111"},
+	'4': {"101", "101", "111", "001", "001"},
+	'5': {"111", "100", "111", "001", "111"},
+	'6': {"111", "100", "111", "101", "111"},
+	'7': {"111", "001", "001", "001", "001"},
+	'8': {"111", "101", "111", "101", "111"},
+	'9': {"111", "101", "111", "001", "111"},
+	'-': {"000", "000", "111", "000", "000"},
+	'/': {"001", "001", "010", "100", "100"},
+	':': {"000", "010", "000", "010", "000"},
+	' ': {"000", "000", "000", "000", "000"},
+	'A': {"010", "101", "111", "101", "101"},
+	'D': {"110", "101", "101", "101", "110"},
+	'E': {"111", "100", "110", "100", "111"},
+	'F': {"111", "100", "110", "100", "100"},
+	'H': {"101", "101", "111", "101", "101"},
+	'I': {"111", "010", "010", "010", "111"},
+	'M': {"101", "111", "111", "101", "101"},
+	'N': {"110", "101", "101", "101", "101"},
+	'O': {"111", "101", "101", "101", "111"},
+	'R': {"110", "101", "110", "101", "101"},
+	'S': {"111", "100", "111", "001", "111"},
+	'T': {"111", "010", "010", "010", "010"},
+	'U': {"101", "101", "101", "101", "111"},
+	'W': {"101", "101", "111", "111", "101"},
+}
+
+const (
+	glyphScale  = 2
+	glyphWidth  = 3 * glyphScale
+	glyphHeight = 5 * glyphScale
+	glyphGap    = glyphScale
+)
+
+// textWidth - Width in pixels of a label
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+
+	return n*(glyphWidth+glyphGap) - glyphGap
+}
+
+// drawText - Draw a label with its top left corner at x, y. Unknown characters are skipped
+func drawText(img *image.RGBA, x int, y int, text string, c color.Color) {
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, bits := range glyph {
+				for col, bit := range bits {
+					if bit == '1' {
+						fillRect(img, x+col*glyphScale, y+row*glyphScale, glyphScale, glyphScale, c)
+					}
+				}
+			}
+		}
+		x += glyphWidth + glyphGap
+	}
+}
@@ -0,0 +1,89 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+const (
+	heatmapCellWidth   = 30
+	heatmapCellHeight  = 36
+	heatmapCellGap     = 2
+	heatmapMarginLeft  = 50
+	heatmapMarginTop   = 30
+	heatmapMarginRight = 20
+	heatmapMarginBot   = 20
+)
+
+// HeatmapHot - Colour of the busiest cell, empty cells use Grid
+var HeatmapHot = color.RGBA{0xfd, 0xcb, 0x58, 0xff}
+
+// RenderHeatmap - Draw a grid of values, one row per row label and one column per column label, and encode as PNG
+func RenderHeatmap(w io.Writer, rowLabels []string, colLabels []string, values [][]int64) error {
+	imgW := heatmapMarginLeft + len(colLabels)*heatmapCellWidth + heatmapMarginRight
+	imgH := heatmapMarginTop + len(rowLabels)*heatmapCellHeight + heatmapMarginBot
+	img := image.NewRGBA(image.Rect(0, 0, imgW, imgH))
+	draw.Draw(img, img.Bounds(), &image.Uniform{Background}, image.Point{}, draw.Src)
+
+	max := int64(0)
+	for _, row := range values {
+		for _, v := range row {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	// Every other column label so two digit hours don't crowd each other
+	for col, label := range colLabels {
+		if col%2 != 0 {
+			continue
+		}
+		x := heatmapMarginLeft + col*heatmapCellWidth + heatmapCellWidth/2 - textWidth(label)/2
+		drawText(img, x, heatmapMarginTop-glyphHeight-8, label, Foreground)
+	}
+
+	for row, label := range rowLabels {
+		y := heatmapMarginTop + row*heatmapCellHeight
+		drawText(img, heatmapMarginLeft-8-textWidth(label), y+heatmapCellHeight/2-glyphHeight/2, label, Foreground)
+
+		for col := range colLabels {
+			v := int64(0)
+			if row < len(values) && col < len(values[row]) {
+				v = values[row][col]
+			}
+			x := heatmapMarginLeft + col*heatmapCellWidth
+			fillRect(img, x, y, heatmapCellWidth-heatmapCellGap, heatmapCellHeight-heatmapCellGap, heat(v, max))
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// heat - Blend from Grid to HeatmapHot by share of the maximum
+func heat(v int64, max int64) color.RGBA {
+	if max == 0 || v <= 0 {
+		return Grid
+	}
+
+	t := float64(v) / float64(max)
+	blend := func(from uint8, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*t)
+	}
+
+	return color.RGBA{blend(Grid.R, HeatmapHot.R), blend(Grid.G, HeatmapHot.G), blend(Grid.B, HeatmapHot.B), 0xff}
+}
+
+// HourLabels - 00 to 23, for heatmap columns
+func HourLabels() []string {
+	labels := make([]string, 24)
+	for hour := range labels {
+		labels[hour] = fmt.Sprintf("%02d", hour)
+	}
+
+	return labels
+}
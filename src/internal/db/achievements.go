@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Achievement struct {
+	GuildID   string
+	UserID    string
+	Key       string
+	AwardedAt time.Time
+}
+
+// UserActivity - Lifetime totals used by achievement rules
+type UserActivity struct {
+	Total    int64
+	Distinct int64
+}
+
+// AwardAchievement - Record an achievement for a user, false if they already had it
+func (db *Database) AwardAchievement(guildID, userID, key string) (bool, error) {
+	res, err := db.db.Exec(
+		"INSERT OR IGNORE INTO `achievement` (`guild_id`, `user_id`, `achievement`, `awarded_at`) VALUES (?,?,?, datetime())",
+		guildID, userID, key,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+// AwardUniqueAchievement - Record an achievement only one user in a guild can hold, false if anyone already has it
+func (db *Database) AwardUniqueAchievement(guildID, userID, key string) (bool, error) {
+	res, err := db.db.Exec(
+		"INSERT OR IGNORE INTO `achievement` (`guild_id`, `user_id`, `achievement`, `awarded_at`) "+
+			"SELECT ?, ?, ?, datetime() WHERE NOT EXISTS (SELECT 1 FROM `achievement` WHERE `guild_id` = ? AND `achievement` = ?)",
+		guildID, userID, key, guildID, key,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+// HasGuildAchievement - Whether anyone in a guild holds an achievement
+func (db *Database) HasGuildAchievement(guildID, key string) (bool, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `achievement` WHERE `guild_id` = ? AND `achievement` = ?",
+		guildID, key,
+	).Scan(&count)
+
+	return count > 0, err
+}
+
+// GetAchievementsForUser - Achievements a user holds in a guild, oldest first
+func (db *Database) GetAchievementsForUser(guildID, userID string) ([]Achievement, error) {
+	data := make([]Achievement, 0)
+	row, err := db.db.Query(
+		"SELECT guild_id, user_id, achievement, awarded_at FROM `achievement` WHERE `guild_id` = ? AND `user_id` = ? ORDER BY awarded_at, id",
+		guildID, userID,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		achievement := Achievement{}
+		row.Scan(&achievement.GuildID, &achievement.UserID, &achievement.Key, &achievement.AwardedAt)
+		data = append(data, achievement)
+	}
+
+	return data, nil
+}
+
+// GetUserActivityForGuild - Total reactions and distinct emojis for a user
+func (db *Database) GetUserActivityForGuild(guildID, userID string) (UserActivity, error) {
+	activity := UserActivity{}
+	err := db.db.QueryRow(
+		"SELECT count(*), count(DISTINCT "+emojiKey+") FROM `emoji_usage` WHERE `guild_id` = ? AND `user_id` = ?",
+		guildID, userID,
+	).Scan(&activity.Total, &activity.Distinct)
+
+	return activity, err
+}
+
+// GetTopUserForGuildBetween - User with the most reactions in [from, to), empty if nobody reacted
+func (db *Database) GetTopUserForGuildBetween(guildID string, from time.Time, to time.Time) (EmojiMap, error) {
+	top := EmojiMap{}
+	row, err := db.db.Query(
+		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `timestamp` >= ? AND `timestamp` < ? "+
+			"GROUP BY user_id ORDER BY count(*) DESC, min(timestamp) LIMIT 1",
+		guildID,
+		from.UTC().Format(timestampLayout),
+		to.UTC().Format(timestampLayout),
+	)
+	if err != nil {
+		return top, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		row.Scan(&top.EmojiID, &top.Count)
+	}
+
+	return top, nil
+}
+
+// GetGuildAchievementChannel - Channel achievements are announced in, empty if announcements are off
+func (db *Database) GetGuildAchievementChannel(guildID string) (string, error) {
+	var channelID string
+	err := db.db.QueryRow(
+		"SELECT achievement_channel_id FROM `guild_settings` WHERE `guild_id` = ?",
+		guildID,
+	).Scan(&channelID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return channelID, err
+}
+
+// SetGuildAchievementChannel - Set the channel achievements are announced in, empty to turn announcements off
+func (db *Database) SetGuildAchievementChannel(guildID, channelID string) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `guild_settings` (`guild_id`, `achievement_channel_id`) VALUES (?,?) "+
+			"ON CONFLICT(`guild_id`) DO UPDATE SET `achievement_channel_id` = excluded.achievement_channel_id",
+		guildID, channelID,
+	)
+
+	return err
+}
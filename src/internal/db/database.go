@@ -60,6 +60,16 @@ func (db *Database) runMigrations() (*Database, error) {
 		return db, err
 	}
 
+	err = db.addColumnIfMissing("emoji_usage", "message_author_id", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE INDEX IF NOT EXISTS `idx_emoji_usage_guild_id_message_author_id` ON `emoji_usage` (`guild_id`, `message_author_id`)")
+	if err != nil {
+		return db, err
+	}
+
 	// Clean up old tables
 	_, err = db.db.Exec("DROP TABLE IF EXISTS `auto_scrubber`")
 	if err != nil {
@@ -80,9 +90,201 @@ func (db *Database) runMigrations() (*Database, error) {
 		return db, err
 	}
 
+	err = db.addColumnIfMissing("scrub", "added_by", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	// NULL for scrubs that last until removed
+	err = db.addColumnIfMissing("scrub", "expires_at", "DATETIME")
+	if err != nil {
+		return db, err
+	}
+
+	// Empty for rules that match any emoji or any channel
+	err = db.addColumnIfMissing("scrub", "emoji", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("scrub", "channel_id", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	// Shown by /list-magic-tools, created_at is NULL for scrubs added before it was tracked
+	err = db.addColumnIfMissing("scrub", "created_at", "DATETIME")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("scrub", "reason", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("scrub", "removed_count", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `digest` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`channel_id` TEXT, " +
+		"`schedule` TEXT, " +
+		"`post_time` TEXT, " +
+		"`last_posted_at` DATETIME" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_digest_guild_id` ON `digest` (`guild_id`)")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `starboard` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`channel_id` TEXT, " +
+		"`emoji` TEXT, " +
+		"`emoji_display` TEXT, " +
+		"`threshold` INTEGER, " +
+		"`remove_below` BOOLEAN, " +
+		"`ignored_channels` TEXT" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_starboard_guild_id` ON `starboard` (`guild_id`)")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `starboard_entry` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`channel_id` TEXT, " +
+		"`message_id` TEXT, " +
+		"`starboard_message_id` TEXT, " +
+		"`count` INTEGER" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_starboard_entry_guild_id_message_id` ON `starboard_entry` (`guild_id`, `message_id`)")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `guild_settings` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`timezone` TEXT NOT NULL DEFAULT ''" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_guild_settings_guild_id` ON `guild_settings` (`guild_id`)")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "achievement_channel_id", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	// Spam guard, defaults match DefaultSpamGuard
+	err = db.addColumnIfMissing("guild_settings", "spam_enabled", "BOOLEAN NOT NULL DEFAULT 0")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "spam_max_reactions", "INTEGER NOT NULL DEFAULT 15")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "spam_window_seconds", "INTEGER NOT NULL DEFAULT 10")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "spam_same_emoji_messages", "INTEGER NOT NULL DEFAULT 6")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "spam_scrub_minutes", "INTEGER NOT NULL DEFAULT 30")
+	if err != nil {
+		return db, err
+	}
+
+	err = db.addColumnIfMissing("guild_settings", "modlog_channel_id", "TEXT NOT NULL DEFAULT ''")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `achievement` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`user_id` TEXT, " +
+		"`achievement` TEXT, " +
+		"`awarded_at` DATETIME" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_achievement_guild_id_user_id_achievement` ON `achievement` (`guild_id`, `user_id`, `achievement`)")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE TABLE IF NOT EXISTS `emoji_sentiment` (" +
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"`guild_id` TEXT, " +
+		"`emoji_key` TEXT, " +
+		"`sentiment` TEXT" +
+		")")
+	if err != nil {
+		return db, err
+	}
+
+	_, err = db.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS `idx_emoji_sentiment_guild_id_emoji_key` ON `emoji_sentiment` (`guild_id`, `emoji_key`)")
+	if err != nil {
+		return db, err
+	}
+
 	return db, nil
 }
 
+// addColumnIfMissing - sqlite has no ADD COLUMN IF NOT EXISTS, so check table_info first
+func (db *Database) addColumnIfMissing(table, column, definition string) error {
+	row, err := db.db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var name string
+		row.Scan(&name)
+		if name == column {
+			return nil
+		}
+	}
+
+	_, err = db.db.Exec("ALTER TABLE `" + table + "` ADD COLUMN `" + column + "` " + definition)
+	return err
+}
+
 // CloseDbConn - Closes DB connection
 func (db *Database) CloseDbConn() {
 	db.db.Close()
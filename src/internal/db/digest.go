@@ -0,0 +1,78 @@
+package db
+
+import (
+	"time"
+)
+
+type Digest struct {
+	GuildID      string
+	ChannelID    string
+	Schedule     string
+	PostTime     string
+	LastPostedAt time.Time
+}
+
+// SetDigest - Create or replace the digest config for a guild
+func (db *Database) SetDigest(guildID, channelID, schedule, postTime string, lastPostedAt time.Time) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `digest` (`guild_id`, `channel_id`, `schedule`, `post_time`, `last_posted_at`) VALUES (?,?,?,?,?) "+
+			"ON CONFLICT(`guild_id`) DO UPDATE SET `channel_id` = excluded.channel_id, `schedule` = excluded.schedule, "+
+			"`post_time` = excluded.post_time, `last_posted_at` = excluded.last_posted_at",
+		guildID, channelID, schedule, postTime, lastPostedAt.UTC().Format(timestampLayout),
+	)
+
+	return err
+}
+
+// RemoveDigest - Delete the digest config for a guild
+func (db *Database) RemoveDigest(guildID string) error {
+	_, err := db.db.Exec(
+		"DELETE FROM `digest` WHERE `guild_id` = ?",
+		guildID,
+	)
+
+	return err
+}
+
+// GetAllDigests - Get all digest configs
+func (db *Database) GetAllDigests() ([]Digest, error) {
+	data := make([]Digest, 0)
+	row, err := db.db.Query("SELECT guild_id, channel_id, schedule, post_time, last_posted_at FROM `digest`")
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		digest := Digest{}
+		row.Scan(&digest.GuildID, &digest.ChannelID, &digest.Schedule, &digest.PostTime, &digest.LastPostedAt)
+		data = append(data, digest)
+	}
+
+	return data, nil
+}
+
+// ClaimDigest - Mark a digest as posted for a scheduled time, false if it was already claimed
+func (db *Database) ClaimDigest(guildID string, scheduledAt time.Time) (bool, error) {
+	scheduled := scheduledAt.UTC().Format(timestampLayout)
+	res, err := db.db.Exec(
+		"UPDATE `digest` SET `last_posted_at` = ? WHERE `guild_id` = ? AND `last_posted_at` < ?",
+		scheduled, guildID, scheduled,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+// UnclaimDigest - Put back the previous last posted time after a failed post, unless someone else has claimed it since
+func (db *Database) UnclaimDigest(guildID string, scheduledAt time.Time, previous time.Time) error {
+	_, err := db.db.Exec(
+		"UPDATE `digest` SET `last_posted_at` = ? WHERE `guild_id` = ? AND `last_posted_at` = ?",
+		previous.UTC().Format(timestampLayout), guildID, scheduledAt.UTC().Format(timestampLayout),
+	)
+
+	return err
+}
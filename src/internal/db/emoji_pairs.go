@@ -0,0 +1,78 @@
+package db
+
+// EmojiPair - Two emojis and the number of messages they were both used on
+type EmojiPair struct {
+	A     string
+	B     string
+	Count int64
+}
+
+// EmojiCooccurrence - Everything needed to score emoji pairs for a guild
+type EmojiCooccurrence struct {
+	// Messages - Messages with at least one reaction
+	Messages int64
+	// Emojis - Messages each emoji was used on, keyed by emoji key
+	Emojis map[string]EmojiMap
+	Pairs  []EmojiPair
+}
+
+// GetEmojiCooccurrenceForGuild - Emojis used together on the same message in the last x days (0 for all time),
+// skipping pairs seen on fewer than minTogether messages
+func (db *Database) GetEmojiCooccurrenceForGuild(guildID string, days int64, minTogether int64) (EmojiCooccurrence, error) {
+	data := EmojiCooccurrence{Emojis: make(map[string]EmojiMap), Pairs: make([]EmojiPair, 0)}
+
+	// One row per emoji per message, however many people reacted with it
+	messageEmojis := "SELECT message_id, " + emojiKey + " AS emoji_key, max(emoji_name) AS emoji_name FROM `emoji_usage` " +
+		"WHERE `guild_id` = ? AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) GROUP BY message_id, emoji_key"
+
+	err := db.db.QueryRow(
+		"SELECT count(DISTINCT message_id) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		days,
+		days,
+	).Scan(&data.Messages)
+	if err != nil {
+		return data, err
+	}
+
+	row, err := db.db.Query(
+		"SELECT emoji_key, max(emoji_name), count(*) FROM ("+messageEmojis+") GROUP BY emoji_key",
+		guildID,
+		days,
+		days,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiID string
+		var emojiName string
+		var count int64
+		row.Scan(&emojiID, &emojiName, &count)
+		data.Emojis[emojiID] = EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count}
+	}
+
+	pairs, err := db.db.Query(
+		"WITH m AS ("+messageEmojis+") "+
+			"SELECT a.emoji_key, b.emoji_key, count(*) FROM m a JOIN m b ON a.message_id = b.message_id AND a.emoji_key < b.emoji_key "+
+			"GROUP BY a.emoji_key, b.emoji_key HAVING count(*) >= ?",
+		guildID,
+		days,
+		days,
+		minTogether,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer pairs.Close()
+	for pairs.Next() {
+		pair := EmojiPair{}
+		pairs.Scan(&pair.A, &pair.B, &pair.Count)
+		data.Pairs = append(data.Pairs, pair)
+	}
+
+	return data, nil
+}
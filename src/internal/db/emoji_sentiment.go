@@ -0,0 +1,44 @@
+package db
+
+// GetEmojiSentiments - Sentiment overrides for a guild keyed by emoji key
+func (db *Database) GetEmojiSentiments(guildID string) (map[string]string, error) {
+	data := make(map[string]string)
+	row, err := db.db.Query(
+		"SELECT emoji_key, sentiment FROM `emoji_sentiment` WHERE `guild_id` = ?",
+		guildID,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var key string
+		var sentiment string
+		row.Scan(&key, &sentiment)
+		data[key] = sentiment
+	}
+
+	return data, nil
+}
+
+// SetEmojiSentiment - Override the sentiment of an emoji for a guild
+func (db *Database) SetEmojiSentiment(guildID, emojiKey, sentiment string) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `emoji_sentiment` (`guild_id`, `emoji_key`, `sentiment`) VALUES (?,?,?) "+
+			"ON CONFLICT(`guild_id`, `emoji_key`) DO UPDATE SET `sentiment` = excluded.sentiment",
+		guildID, emojiKey, sentiment,
+	)
+
+	return err
+}
+
+// RemoveEmojiSentiment - Drop a guild's override so the emoji goes back to the default lexicon
+func (db *Database) RemoveEmojiSentiment(guildID, emojiKey string) error {
+	_, err := db.db.Exec(
+		"DELETE FROM `emoji_sentiment` WHERE `guild_id` = ? AND `emoji_key` = ?",
+		guildID, emojiKey,
+	)
+
+	return err
+}
@@ -0,0 +1,357 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// emojiKey - Stock emojis have no ID, so they are keyed by name instead
+const emojiKey = "CASE WHEN `emoji_id` = '' THEN `emoji_name` ELSE `emoji_id` END"
+
+// timestampLayout - Format written by sqlite's datetime()
+const timestampLayout = "2006-01-02 15:04:05"
+
+type EmojiStats struct {
+	EmojiID   string
+	EmojiName string
+	Count     int64
+	Users     int64
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// parseTimestamp - Parse an aggregated timestamp, sqlite returns these as text
+func parseTimestamp(value string) time.Time {
+	t, err := time.Parse(timestampLayout, value)
+	if err != nil {
+		t, _ = time.Parse(time.RFC3339, value)
+	}
+
+	return t
+}
+
+// GetEmojiStatsForGuild - Totals for a single emoji
+func (db *Database) GetEmojiStatsForGuild(guildID string, emojiID string) (EmojiStats, error) {
+	stats := EmojiStats{EmojiID: emojiID}
+	var emojiName, firstSeen, lastSeen *string
+	err := db.db.QueryRow(
+		"SELECT max(emoji_name), count(*), count(DISTINCT user_id), min(timestamp), max(timestamp) FROM `emoji_usage` "+
+			"WHERE `guild_id` = ? AND (`emoji_id` = ? OR `emoji_name` = ?)",
+		guildID,
+		emojiID,
+		emojiID,
+	).Scan(&emojiName, &stats.Count, &stats.Users, &firstSeen, &lastSeen)
+	if err != nil {
+		return stats, err
+	}
+
+	if emojiName != nil {
+		stats.EmojiName = *emojiName
+	}
+	if firstSeen != nil {
+		stats.FirstSeen = parseTimestamp(*firstSeen)
+	}
+	if lastSeen != nil {
+		stats.LastSeen = parseTimestamp(*lastSeen)
+	}
+
+	return stats, nil
+}
+
+// GetEmojiRankForGuild - 1 based rank of an emoji by usage
+func (db *Database) GetEmojiRankForGuild(guildID string, emojiID string) (int64, error) {
+	var rank int64
+	err := db.db.QueryRow(
+		"SELECT count(*) + 1 FROM (SELECT count(*) AS total FROM `emoji_usage` WHERE `guild_id` = ? GROUP BY "+emojiKey+") "+
+			"WHERE total > (SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (`emoji_id` = ? OR `emoji_name` = ?))",
+		guildID,
+		guildID,
+		emojiID,
+		emojiID,
+	).Scan(&rank)
+
+	return rank, err
+}
+
+// GetTopChannelsForGuildEmoji - Report usage
+func (db *Database) GetTopChannelsForGuildEmoji(guildID string, emojiID string, num int) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT channel_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (`emoji_id` = ? OR `emoji_name` = ?) GROUP BY channel_id ORDER BY count(*) DESC LIMIT ?",
+		guildID,
+		emojiID,
+		emojiID,
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var name string
+		var count int64
+		row.Scan(&name, &count)
+		data[i] = EmojiMap{EmojiID: name, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
+// GetWeeklyUsageForGuildEmoji - Uses per week, oldest week first
+func (db *Database) GetWeeklyUsageForGuildEmoji(guildID string, emojiID string, weeks int) ([]int64, error) {
+	data := make([]int64, weeks)
+	row, err := db.db.Query(
+		"SELECT CAST((julianday('now') - julianday(timestamp)) / 7 AS INTEGER) AS weeks_ago, count(*) FROM `emoji_usage` "+
+			"WHERE `guild_id` = ? AND (`emoji_id` = ? OR `emoji_name` = ?) AND timestamp >= datetime('now', ?) GROUP BY weeks_ago",
+		guildID,
+		emojiID,
+		emojiID,
+		fmt.Sprintf("-%d days", weeks*7),
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var weeksAgo int
+		var count int64
+		row.Scan(&weeksAgo, &count)
+		if weeksAgo >= 0 && weeksAgo < weeks {
+			data[weeks-1-weeksAgo] = count
+		}
+	}
+
+	return data, nil
+}
+
+// GetRecentEmojisForGuild - Most recently used distinct emojis
+func (db *Database) GetRecentEmojisForGuild(guildID string, num int) ([]EmojiMap, error) {
+	data := make([]EmojiMap, 0)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*) FROM `emoji_usage` WHERE `guild_id` = ? "+
+			"GROUP BY emoji_key ORDER BY max(timestamp) DESC LIMIT ?",
+		guildID,
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiName string
+		var emojiID string
+		var count int64
+		row.Scan(&emojiName, &emojiID, &count)
+		data = append(data, EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count})
+	}
+
+	return data, nil
+}
+
+// GetUserRankForGuild - 1 based rank of a user by reactions given
+func (db *Database) GetUserRankForGuild(guildID string, userID string) (int64, error) {
+	var rank int64
+	err := db.db.QueryRow(
+		"SELECT count(*) + 1 FROM (SELECT count(*) AS total FROM `emoji_usage` WHERE `guild_id` = ? GROUP BY user_id) "+
+			"WHERE total > (SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `user_id` = ?)",
+		guildID,
+		guildID,
+		userID,
+	).Scan(&rank)
+
+	return rank, err
+}
+
+type EmojiActivity struct {
+	EmojiID  string
+	Count    int64
+	LastSeen time.Time
+}
+
+// GetCustomEmojiActivityForGuild - Uses in the last x days and last use ever, keyed by custom emoji ID
+func (db *Database) GetCustomEmojiActivityForGuild(guildID string, days int64) (map[string]EmojiActivity, error) {
+	data := make(map[string]EmojiActivity)
+	row, err := db.db.Query(
+		"SELECT emoji_id, sum(timestamp >= datetime('now', ?)), max(timestamp) FROM `emoji_usage` "+
+			"WHERE `guild_id` = ? AND `emoji_id` != '' AND `emoji_id` != `emoji_name` GROUP BY emoji_id",
+		fmt.Sprintf("-%d days", days),
+		guildID,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiID string
+		var count int64
+		var lastSeen string
+		row.Scan(&emojiID, &count, &lastSeen)
+		data[emojiID] = EmojiActivity{EmojiID: emojiID, Count: count, LastSeen: parseTimestamp(lastSeen)}
+	}
+
+	return data, nil
+}
+
+type EmojiTimeline struct {
+	EmojiID   string
+	EmojiName string
+	Counts    []int64
+}
+
+// GetEmojiTimelinesForGuild - Per emoji usage split into buckets of x days, oldest bucket first
+func (db *Database) GetEmojiTimelinesForGuild(guildID string, bucketDays int, buckets int) (map[string]EmojiTimeline, error) {
+	return db.GetEmojiTimelinesForChannel(guildID, "", bucketDays, buckets)
+}
+
+// GetEmojiTimelinesForChannel - Per emoji usage in a channel (empty for all) split into buckets of x days, oldest bucket first
+func (db *Database) GetEmojiTimelinesForChannel(guildID string, channelID string, bucketDays int, buckets int) (map[string]EmojiTimeline, error) {
+	data := make(map[string]EmojiTimeline)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, CAST((julianday('now') - julianday(timestamp)) / ? AS INTEGER) AS bucket, count(*) "+
+			"FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND timestamp >= datetime('now', ?) GROUP BY emoji_key, bucket",
+		bucketDays,
+		guildID,
+		channelID,
+		channelID,
+		fmt.Sprintf("-%d days", bucketDays*buckets),
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiName string
+		var emojiID string
+		var bucket int
+		var count int64
+		row.Scan(&emojiName, &emojiID, &bucket, &count)
+		if bucket < 0 || bucket >= buckets {
+			continue
+		}
+
+		timeline, ok := data[emojiID]
+		if !ok {
+			timeline = EmojiTimeline{EmojiID: emojiID, EmojiName: emojiName, Counts: make([]int64, buckets)}
+		}
+		timeline.Counts[buckets-1-bucket] += count
+		data[emojiID] = timeline
+	}
+
+	return data, nil
+}
+
+// GetEmojiTimelinesForGuildBetween - Per emoji usage from (inclusive) to (exclusive) split into equal buckets, oldest bucket first
+func (db *Database) GetEmojiTimelinesForGuildBetween(guildID string, from time.Time, to time.Time, buckets int) (map[string]EmojiTimeline, error) {
+	data := make(map[string]EmojiTimeline)
+	bucketDays := to.Sub(from).Hours() / 24 / float64(buckets)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, CAST((julianday(?) - julianday(timestamp)) / ? AS INTEGER) AS bucket, count(*) "+
+			"FROM `emoji_usage` WHERE `guild_id` = ? AND `timestamp` >= ? AND `timestamp` < ? GROUP BY emoji_key, bucket",
+		to.UTC().Format(timestampLayout),
+		bucketDays,
+		guildID,
+		from.UTC().Format(timestampLayout),
+		to.UTC().Format(timestampLayout),
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiName string
+		var emojiID string
+		var bucket int
+		var count int64
+		row.Scan(&emojiName, &emojiID, &bucket, &count)
+		if bucket < 0 || bucket >= buckets {
+			continue
+		}
+
+		timeline, ok := data[emojiID]
+		if !ok {
+			timeline = EmojiTimeline{EmojiID: emojiID, EmojiName: emojiName, Counts: make([]int64, buckets)}
+		}
+		timeline.Counts[buckets-1-bucket] += count
+		data[emojiID] = timeline
+	}
+
+	return data, nil
+}
+
+// GetUserTimelineForGuild - Reactions by a user split into buckets of x days, oldest bucket first
+func (db *Database) GetUserTimelineForGuild(guildID string, userID string, bucketDays int, buckets int) ([]int64, error) {
+	data := make([]int64, buckets)
+	row, err := db.db.Query(
+		"SELECT CAST((julianday('now') - julianday(timestamp)) / ? AS INTEGER) AS bucket, count(*) "+
+			"FROM `emoji_usage` WHERE `guild_id` = ? AND `user_id` = ? AND timestamp >= datetime('now', ?) GROUP BY bucket",
+		bucketDays,
+		guildID,
+		userID,
+		fmt.Sprintf("-%d days", bucketDays*buckets),
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var bucket int
+		var count int64
+		row.Scan(&bucket, &count)
+		if bucket >= 0 && bucket < buckets {
+			data[buckets-1-bucket] += count
+		}
+	}
+
+	return data, nil
+}
+
+// GetHourlyUsageForGuild - Reactions per UTC hour in the last x days (0 for all time), optionally for one
+// channel, user or emoji. Hours are left in UTC so callers can convert to any timezone
+func (db *Database) GetHourlyUsageForGuild(guildID, channelID, userID, emojiID string, days int64) (map[time.Time]int64, error) {
+	data := make(map[time.Time]int64)
+	row, err := db.db.Query(
+		"SELECT strftime('%Y-%m-%d %H:00:00', timestamp) AS hour, count(*) FROM `emoji_usage` WHERE `guild_id` = ? "+
+			"AND (? = '' OR `channel_id` = ?) AND (? = '' OR `user_id` = ?) AND (? = '' OR `emoji_id` = ? OR `emoji_name` = ?) "+
+			"AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) GROUP BY hour",
+		guildID,
+		channelID,
+		channelID,
+		userID,
+		userID,
+		emojiID,
+		emojiID,
+		emojiID,
+		days,
+		days,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var hour string
+		var count int64
+		row.Scan(&hour, &count)
+		data[parseTimestamp(hour)] += count
+	}
+
+	return data, nil
+}
@@ -22,11 +22,11 @@ type EmojiUsage struct {
 	Timestamp time.Time
 }
 
-// LogEmojiUsage - Log usage
-func (db *Database) LogEmojiUsage(guildID, channelID, messageID, userID, emojiID, emojiName string) error {
+// LogEmojiUsage - Log usage, messageAuthorID is the user who received the reaction
+func (db *Database) LogEmojiUsage(guildID, channelID, messageID, userID, emojiID, emojiName, messageAuthorID string) error {
 	_, err := db.db.Exec(
-		"INSERT INTO `emoji_usage` (`guild_id`, `channel_id`, `message_id`, `user_id`, `emoji_id`, `emoji_name`, `timestamp`) VALUES (?,?,?,?,?,?, datetime())",
-		guildID, channelID, messageID, userID, emojiID, emojiName,
+		"INSERT INTO `emoji_usage` (`guild_id`, `channel_id`, `message_id`, `user_id`, `emoji_id`, `emoji_name`, `message_author_id`, `timestamp`) VALUES (?,?,?,?,?,?,?, datetime())",
+		guildID, channelID, messageID, userID, emojiID, emojiName, messageAuthorID,
 	)
 
 	return err
@@ -62,13 +62,32 @@ func (db *Database) DeleteEmojiAll(guildID, channelID, messageID string) error {
 	return err
 }
 
-// GetTopUsersForGuild - Report usage
-func (db *Database) GetTopUsersForGuild(guildID string, num int64) (map[int]EmojiMap, error) {
+// CountReactionsForMessage - Number of reactions with an emoji on a single message
+func (db *Database) CountReactionsForMessage(guildID, messageID, emojiID string) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `message_id` = ? AND "+emojiKey+" = ?",
+		guildID,
+		messageID,
+		emojiID,
+	).Scan(&count)
+
+	return count, err
+}
+
+// GetTopUsersForGuild - Report usage, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) GetTopUsersForGuild(guildID string, channelID string, days int64, offset int64, num int64) (map[int]EmojiMap, error) {
 	data := make(map[int]EmojiMap)
 	row, err := db.db.Query(
-		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? GROUP BY user_id ORDER BY count(*) DESC LIMIT ?",
+		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"GROUP BY user_id ORDER BY count(*) DESC, user_id LIMIT ? OFFSET ?",
 		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
 		num,
+		offset,
 	)
 
 	if err != nil {
@@ -87,12 +106,18 @@ func (db *Database) GetTopUsersForGuild(guildID string, num int64) (map[int]Emoj
 	return data, nil
 }
 
-// GetTopUsersForGuildEmoji - Report usage
-func (db *Database) GetTopUsersForGuildEmoji(guildID string, emojiID string, num int) (map[int]EmojiMap, error) {
+// GetTopUsersForGuildEmoji - Report usage, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) GetTopUsersForGuildEmoji(guildID string, channelID string, days int64, emojiID string, num int) (map[int]EmojiMap, error) {
 	data := make(map[int]EmojiMap)
 	row, err := db.db.Query(
-		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `emoji_id` = ? GROUP BY emoji_id, user_id ORDER BY count(*) DESC LIMIT ?",
+		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"AND (`emoji_id` = ? OR `emoji_name` = ?) GROUP BY user_id ORDER BY count(*) DESC LIMIT ?",
 		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+		emojiID,
 		emojiID,
 		num,
 	)
@@ -114,13 +139,19 @@ func (db *Database) GetTopUsersForGuildEmoji(guildID string, emojiID string, num
 	return data, nil
 }
 
-// GetTopEmojisForGuild - Report usage
-func (db *Database) GetTopEmojisForGuild(guildID string, num int64) (map[int]EmojiMap, error) {
+// GetTopEmojisForGuild - Report usage, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) GetTopEmojisForGuild(guildID string, channelID string, days int64, offset int64, num int64) (map[int]EmojiMap, error) {
 	data := make(map[int]EmojiMap)
 	row, err := db.db.Query(
-		"SELECT emoji_name, emoji_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? GROUP BY emoji_id ORDER BY count(*) DESC LIMIT ?",
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"GROUP BY emoji_key ORDER BY count(*) DESC, emoji_key LIMIT ? OFFSET ?",
 		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
 		num,
+		offset,
 	)
 
 	if err != nil {
@@ -141,12 +172,17 @@ func (db *Database) GetTopEmojisForGuild(guildID string, num int64) (map[int]Emo
 	return data, nil
 }
 
-// GetTopEmojisForGuildUser - Report usage
-func (db *Database) GetTopEmojisForGuildUser(guildID string, userID string, num int) (map[int]EmojiMap, error) {
+// GetTopEmojisForGuildUser - Report usage, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) GetTopEmojisForGuildUser(guildID string, channelID string, days int64, userID string, num int) (map[int]EmojiMap, error) {
 	data := make(map[int]EmojiMap)
 	row, err := db.db.Query(
-		"SELECT emoji_name, emoji_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `user_id` = ? GROUP BY emoji_name ORDER BY count(*) DESC LIMIT ?",
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"AND `user_id` = ? GROUP BY emoji_key ORDER BY count(*) DESC LIMIT ?",
 		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
 		userID,
 		num,
 	)
@@ -169,6 +205,244 @@ func (db *Database) GetTopEmojisForGuildUser(guildID string, userID string, num
 	return data, nil
 }
 
+// CountUsageForGuild - Number of reactions, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) CountUsageForGuild(guildID string, channelID string, days int64) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+	).Scan(&count)
+
+	return count, err
+}
+
+// CountUsageForGuildBetween - Number of reactions logged from (inclusive) to (exclusive)
+func (db *Database) CountUsageForGuildBetween(guildID string, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `timestamp` >= ? AND `timestamp` < ?",
+		guildID,
+		from.UTC().Format(timestampLayout),
+		to.UTC().Format(timestampLayout),
+	).Scan(&count)
+
+	return count, err
+}
+
+// GetTopEmojisForGuildBetween - Most used emojis from (inclusive) to (exclusive)
+func (db *Database) GetTopEmojisForGuildBetween(guildID string, from time.Time, to time.Time, num int64) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `timestamp` >= ? AND `timestamp` < ? "+
+			"GROUP BY emoji_key ORDER BY count(*) DESC, emoji_key LIMIT ?",
+		guildID,
+		from.UTC().Format(timestampLayout),
+		to.UTC().Format(timestampLayout),
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var emojiName string
+		var emojiID string
+		var count int64
+		row.Scan(&emojiName, &emojiID, &count)
+		data[i] = EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
+// GetTopUsersForGuildBetween - Users with the most reactions from (inclusive) to (exclusive)
+func (db *Database) GetTopUsersForGuildBetween(guildID string, from time.Time, to time.Time, num int64) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT user_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `timestamp` >= ? AND `timestamp` < ? "+
+			"GROUP BY user_id ORDER BY count(*) DESC, user_id LIMIT ?",
+		guildID,
+		from.UTC().Format(timestampLayout),
+		to.UTC().Format(timestampLayout),
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var name string
+		var count int64
+		row.Scan(&name, &count)
+		data[i] = EmojiMap{EmojiID: name, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
+// CountUsersForGuild - Number of users that have reacted, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) CountUsersForGuild(guildID string, channelID string, days int64) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(DISTINCT user_id) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+	).Scan(&count)
+
+	return count, err
+}
+
+// CountEmojisForGuild - Number of distinct emojis used, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) CountEmojisForGuild(guildID string, channelID string, days int64) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(DISTINCT "+emojiKey+") FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+	).Scan(&count)
+
+	return count, err
+}
+
+// CountReceiversForGuild - Number of users that have received reactions, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) CountReceiversForGuild(guildID string, channelID string, days int64) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(DISTINCT message_author_id) FROM `emoji_usage` WHERE `guild_id` = ? AND `message_author_id` != '' AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+	).Scan(&count)
+
+	return count, err
+}
+
+// CountReceivedForGuild - Number of reactions with a known message author, optionally limited to a channel and the last x days (0 for all time)
+func (db *Database) CountReceivedForGuild(guildID string, channelID string, days int64) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `message_author_id` != '' AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+	).Scan(&count)
+
+	return count, err
+}
+
+// GetTopReceiversForGuild - Users whose messages received the most reactions
+func (db *Database) GetTopReceiversForGuild(guildID string, channelID string, days int64, offset int64, num int64) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT message_author_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `message_author_id` != '' AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"GROUP BY message_author_id ORDER BY count(*) DESC, message_author_id LIMIT ? OFFSET ?",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+		num,
+		offset,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var name string
+		var count int64
+		row.Scan(&name, &count)
+		data[i] = EmojiMap{EmojiID: name, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
+// GetTopEmojisForGuildReceiver - Emojis a user's messages received the most
+func (db *Database) GetTopEmojisForGuildReceiver(guildID string, channelID string, days int64, messageAuthorID string, num int) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND (? = '' OR `channel_id` = ?) AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"AND `message_author_id` = ? GROUP BY emoji_key ORDER BY count(*) DESC LIMIT ?",
+		guildID,
+		channelID,
+		channelID,
+		days,
+		days,
+		messageAuthorID,
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var emojiName string
+		var emojiID string
+		var count int64
+		row.Scan(&emojiName, &emojiID, &count)
+		data[i] = EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
+// GetTopChannelsForGuild - Report usage
+func (db *Database) GetTopChannelsForGuild(guildID string, num int64) (map[int]EmojiMap, error) {
+	data := make(map[int]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT channel_id, count(*) FROM `emoji_usage` WHERE `guild_id` = ? GROUP BY channel_id ORDER BY count(*) DESC LIMIT ?",
+		guildID,
+		num,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	i := 0
+	for row.Next() {
+		var name string
+		var count int64
+		row.Scan(&name, &count)
+		data[i] = EmojiMap{EmojiID: name, Count: count}
+		i++
+	}
+
+	return data, nil
+}
+
 // GetRecentEmojisForUser - Get recent emojis used by user map[]
 func (db *Database) GetRecentEmojisForUser(guildID string, userID string, hours int64) ([]EmojiUsage, error) {
 	var data []EmojiUsage
@@ -0,0 +1,68 @@
+package db
+
+// GetEmojiVectorsForGuild - Per user emoji counts in the last x days (0 for all time), for the most active
+// num users with at least minUses reactions. map[UserID]map[EmojiKey]EmojiMap
+func (db *Database) GetEmojiVectorsForGuild(guildID string, days int64, minUses int64, num int64) (map[string]map[string]EmojiMap, error) {
+	data := make(map[string]map[string]EmojiMap)
+	row, err := db.db.Query(
+		"WITH users AS (SELECT user_id FROM `emoji_usage` WHERE `guild_id` = ? AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"GROUP BY user_id HAVING count(*) >= ? ORDER BY count(*) DESC LIMIT ?) "+
+			"SELECT user_id, "+emojiKey+" AS emoji_key, max(emoji_name), count(*) FROM `emoji_usage` "+
+			"WHERE `guild_id` = ? AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) AND user_id IN (SELECT user_id FROM users) "+
+			"GROUP BY user_id, emoji_key",
+		guildID,
+		days,
+		days,
+		minUses,
+		num,
+		guildID,
+		days,
+		days,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var userID string
+		var emojiID string
+		var emojiName string
+		var count int64
+		row.Scan(&userID, &emojiID, &emojiName, &count)
+		if _, ok := data[userID]; !ok {
+			data[userID] = make(map[string]EmojiMap)
+		}
+		data[userID][emojiID] = EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count}
+	}
+
+	return data, nil
+}
+
+// GetEmojiVectorForUser - One user's emoji counts in the last x days (0 for all time). map[EmojiKey]EmojiMap
+func (db *Database) GetEmojiVectorForUser(guildID string, userID string, days int64) (map[string]EmojiMap, error) {
+	data := make(map[string]EmojiMap)
+	row, err := db.db.Query(
+		"SELECT "+emojiKey+" AS emoji_key, max(emoji_name), count(*) FROM `emoji_usage` "+
+			"WHERE `guild_id` = ? AND `user_id` = ? AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) "+
+			"GROUP BY emoji_key",
+		guildID,
+		userID,
+		days,
+		days,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		var emojiID string
+		var emojiName string
+		var count int64
+		row.Scan(&emojiID, &emojiName, &count)
+		data[emojiID] = EmojiMap{EmojiID: emojiID, EmojiName: emojiName, Count: count}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"time"
+)
+
+// GlobalTotals - Usage summed over every guild
+type GlobalTotals struct {
+	Reactions int64
+	Users     int64
+	Emojis    int64
+	Guilds    int64
+}
+
+// GuildActivity - Usage for one guild, for ranking guilds against each other
+type GuildActivity struct {
+	GuildID   string
+	Reactions int64
+	Users     int64
+}
+
+// GlobalEmoji - An emoji's uses across every guild and how many guilds use it
+type GlobalEmoji struct {
+	EmojiMap
+	Guilds int64
+}
+
+// EmojiSpread - A custom emoji seen in more than one guild
+type EmojiSpread struct {
+	EmojiID   string
+	EmojiName string
+	// OriginGuildID - Guild it was first seen in
+	OriginGuildID string
+	Guilds        int64
+	Count         int64
+	FirstSeen     time.Time
+}
+
+// GetGlobalTotals - Totals across every guild in the last x days (0 for all time)
+func (db *Database) GetGlobalTotals(days int64) (GlobalTotals, error) {
+	totals := GlobalTotals{}
+	err := db.db.QueryRow(
+		"SELECT count(*), count(DISTINCT user_id), count(DISTINCT "+emojiKey+"), count(DISTINCT guild_id) FROM `emoji_usage` "+
+			"WHERE (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days'))",
+		days,
+		days,
+	).Scan(&totals.Reactions, &totals.Users, &totals.Emojis, &totals.Guilds)
+
+	return totals, err
+}
+
+// GetTopEmojisGlobal - Most used emojis across every guild in the last x days (0 for all time)
+func (db *Database) GetTopEmojisGlobal(days int64, num int64) ([]GlobalEmoji, error) {
+	data := make([]GlobalEmoji, 0)
+	row, err := db.db.Query(
+		"SELECT max(emoji_name), "+emojiKey+" AS emoji_key, count(*), count(DISTINCT guild_id) FROM `emoji_usage` "+
+			"WHERE (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) GROUP BY emoji_key ORDER BY count(*) DESC LIMIT ?",
+		days,
+		days,
+		num,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		emoji := GlobalEmoji{}
+		row.Scan(&emoji.EmojiName, &emoji.EmojiID, &emoji.Count, &emoji.Guilds)
+		data = append(data, emoji)
+	}
+
+	return data, nil
+}
+
+// GetGuildActivityRanking - Guilds ordered by reactions in the last x days (0 for all time)
+func (db *Database) GetGuildActivityRanking(days int64, num int64) ([]GuildActivity, error) {
+	data := make([]GuildActivity, 0)
+	row, err := db.db.Query(
+		"SELECT guild_id, count(*), count(DISTINCT user_id) FROM `emoji_usage` "+
+			"WHERE (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) GROUP BY guild_id ORDER BY count(*) DESC LIMIT ?",
+		days,
+		days,
+		num,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		activity := GuildActivity{}
+		row.Scan(&activity.GuildID, &activity.Reactions, &activity.Users)
+		data = append(data, activity)
+	}
+
+	return data, nil
+}
+
+// GetEmojiSpread - Custom emojis used in more than one guild, widest spread first
+func (db *Database) GetEmojiSpread(num int64) ([]EmojiSpread, error) {
+	data := make([]EmojiSpread, 0)
+	row, err := db.db.Query(
+		"SELECT e.emoji_id, max(e.emoji_name), "+
+			"(SELECT o.guild_id FROM `emoji_usage` o WHERE o.emoji_id = e.emoji_id ORDER BY o.timestamp, o.id LIMIT 1), "+
+			"count(DISTINCT e.guild_id), count(*), min(e.timestamp) FROM `emoji_usage` e "+
+			"WHERE e.emoji_id != '' GROUP BY e.emoji_id HAVING count(DISTINCT e.guild_id) > 1 "+
+			"ORDER BY count(DISTINCT e.guild_id) DESC, count(*) DESC LIMIT ?",
+		num,
+	)
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		spread := EmojiSpread{}
+		var firstSeen string
+		row.Scan(&spread.EmojiID, &spread.EmojiName, &spread.OriginGuildID, &spread.Guilds, &spread.Count, &firstSeen)
+		spread.FirstSeen = parseTimestamp(firstSeen)
+		data = append(data, spread)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"database/sql"
+)
+
+// GetGuildTimezone - IANA timezone for a guild, empty if it hasn't been set
+func (db *Database) GetGuildTimezone(guildID string) (string, error) {
+	var timezone string
+	err := db.db.QueryRow(
+		"SELECT timezone FROM `guild_settings` WHERE `guild_id` = ?",
+		guildID,
+	).Scan(&timezone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return timezone, err
+}
+
+// SetGuildTimezone - Set the IANA timezone for a guild
+func (db *Database) SetGuildTimezone(guildID, timezone string) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `guild_settings` (`guild_id`, `timezone`) VALUES (?,?) "+
+			"ON CONFLICT(`guild_id`) DO UPDATE SET `timezone` = excluded.timezone",
+		guildID, timezone,
+	)
+
+	return err
+}
@@ -0,0 +1,65 @@
+package db
+
+// ChannelReactionStats - How reacted messages in a channel usually do
+type ChannelReactionStats struct {
+	// Messages - Messages with at least one reaction
+	Messages int64
+	// Average - Reactions per reacted message
+	Average float64
+	// Fewer - Reacted messages with fewer reactions than the one being compared
+	Fewer int64
+}
+
+// GetReactionsForMessage - Every recorded reaction on a message, oldest first
+func (db *Database) GetReactionsForMessage(guildID string, messageID string) ([]EmojiUsage, error) {
+	var data []EmojiUsage
+	row, err := db.db.Query(
+		"SELECT id, guild_id, channel_id, message_id, user_id, emoji_id, emoji_name, timestamp "+
+			"FROM `emoji_usage` WHERE `guild_id` = ? AND `message_id` = ? ORDER BY timestamp, id",
+		guildID,
+		messageID,
+	)
+
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		usage := EmojiUsage{}
+		row.Scan(&usage.ID, &usage.GuildID, &usage.ChannelID, &usage.MessageID, &usage.UserID, &usage.EmojiID, &usage.EmojiName, &usage.Timestamp)
+		data = append(data, usage)
+	}
+
+	return data, nil
+}
+
+// GetReactionStatsForChannel - Average reactions per reacted message in a channel over the last x days (0 for all time),
+// and how many of those messages had fewer than count reactions
+func (db *Database) GetReactionStatsForChannel(guildID string, channelID string, days int64, count int64) (ChannelReactionStats, error) {
+	stats := ChannelReactionStats{}
+	err := db.db.QueryRow(
+		"SELECT count(*), coalesce(avg(reactions), 0), coalesce(sum(reactions < ?), 0) FROM ("+
+			"SELECT count(*) AS reactions FROM `emoji_usage` WHERE `guild_id` = ? AND `channel_id` = ? "+
+			"AND (? = 0 OR `timestamp` >= datetime('now', '-' || ? || ' days')) GROUP BY message_id)",
+		count,
+		guildID,
+		channelID,
+		days,
+		days,
+	).Scan(&stats.Messages, &stats.Average, &stats.Fewer)
+
+	return stats, err
+}
+
+// CountReceivedForGuildUser - Number of reactions a user's messages have received
+func (db *Database) CountReceivedForGuildUser(guildID string, messageAuthorID string) (int64, error) {
+	var count int64
+	err := db.db.QueryRow(
+		"SELECT count(*) FROM `emoji_usage` WHERE `guild_id` = ? AND `message_author_id` = ?",
+		guildID,
+		messageAuthorID,
+	).Scan(&count)
+
+	return count, err
+}
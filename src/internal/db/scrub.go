@@ -1,21 +1,50 @@
 package db
 
+import (
+	"database/sql"
+	"time"
+)
+
 type Scrub struct {
+	ID      int64  `json:"id"`
 	GuildID string `json:"guild_id"`
 	UserID  string `json:"user_id"`
+	// Emoji - Emoji key to remove, empty for any emoji
+	Emoji string `json:"emoji"`
+	// ChannelID - Channel the rule applies in, empty for every channel
+	ChannelID string `json:"channel_id"`
+	// AddedBy - Mod who created it, empty when the bot did (spam guard)
+	AddedBy string `json:"added_by"`
+	// ExpiresAt - Zero for scrubs that last until removed
+	ExpiresAt time.Time `json:"expires_at"`
+	// CreatedAt - Zero for scrubs added before it was tracked
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+	// Removed - Reactions removed by this rule so far
+	Removed int64 `json:"removed_count"`
 }
 
-// AddScrub - Add an auto scrubber for a guild/user
-func (db *Database) AddScrub(guildID, userID string) error {
-	_, err := db.db.Exec(
-		"INSERT INTO `scrub` (`guild_id`, `user_id`) VALUES (?,?)",
-		guildID, userID,
+const scrubColumns = "id, guild_id, user_id, emoji, channel_id, added_by, expires_at, created_at, reason, removed_count"
+
+// AddScrub - Add an auto scrubber rule for a guild/user, a zero ExpiresAt never expires
+func (db *Database) AddScrub(scrub Scrub) (int64, error) {
+	var expires any
+	if !scrub.ExpiresAt.IsZero() {
+		expires = scrub.ExpiresAt.UTC().Format(timestampLayout)
+	}
+
+	res, err := db.db.Exec(
+		"INSERT INTO `scrub` (`guild_id`, `user_id`, `emoji`, `channel_id`, `added_by`, `expires_at`, `created_at`, `reason`) VALUES (?,?,?,?,?,?,?,?)",
+		scrub.GuildID, scrub.UserID, scrub.Emoji, scrub.ChannelID, scrub.AddedBy, expires, time.Now().UTC().Format(timestampLayout), scrub.Reason,
 	)
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	return res.LastInsertId()
 }
 
-// RemoveScrub - Delete for guild/channel/message/user
+// RemoveScrub - Delete every rule for guild/user
 func (db *Database) RemoveScrub(guildID, userID string) error {
 	_, err := db.db.Exec(
 		"DELETE FROM `scrub` WHERE `guild_id` = ? AND `user_id` = ?",
@@ -25,20 +54,86 @@ func (db *Database) RemoveScrub(guildID, userID string) error {
 	return err
 }
 
+// RemoveScrubRule - Delete the rule for guild/user with exactly this emoji and channel, false if there wasn't one
+func (db *Database) RemoveScrubRule(guildID, userID, emoji, channelID string) (bool, error) {
+	res, err := db.db.Exec(
+		"DELETE FROM `scrub` WHERE `guild_id` = ? AND `user_id` = ? AND `emoji` = ? AND `channel_id` = ?",
+		guildID, userID, emoji, channelID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// RemoveScrubByID - Delete a single rule, false if it was already gone
+func (db *Database) RemoveScrubByID(id int64) (bool, error) {
+	res, err := db.db.Exec(
+		"DELETE FROM `scrub` WHERE `id` = ?",
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
 // GetAllScrubs - Get all scrubbers
 func (db *Database) GetAllScrubs() ([]Scrub, error) {
+	return db.getScrubs("SELECT " + scrubColumns + " from `scrub`")
+}
+
+// GetActiveScrubsForGuild - Scrubs in a guild that haven't expired, newest first
+func (db *Database) GetActiveScrubsForGuild(guildID string, now time.Time) ([]Scrub, error) {
+	return db.getScrubs(
+		"SELECT "+scrubColumns+" from `scrub` WHERE `guild_id` = ? AND (`expires_at` IS NULL OR `expires_at` > ?) ORDER BY `id` DESC",
+		guildID,
+		now.UTC().Format(timestampLayout),
+	)
+}
+
+// IncrementScrubRemoved - Count a reaction removed by a scrub
+func (db *Database) IncrementScrubRemoved(id int64) error {
+	_, err := db.db.Exec(
+		"UPDATE `scrub` SET `removed_count` = `removed_count` + 1 WHERE `id` = ?",
+		id,
+	)
+
+	return err
+}
+
+// GetExpiredScrubs - Scrubs whose expiry has passed
+func (db *Database) GetExpiredScrubs(now time.Time) ([]Scrub, error) {
+	return db.getScrubs(
+		"SELECT "+scrubColumns+" from `scrub` WHERE `expires_at` IS NOT NULL AND `expires_at` <= ?",
+		now.UTC().Format(timestampLayout),
+	)
+}
+
+func (db *Database) getScrubs(query string, args ...any) ([]Scrub, error) {
 	data := make([]Scrub, 0)
-	row, err := db.db.Query("SELECT guild_id, user_id from `scrub`")
+	row, err := db.db.Query(query, args...)
 	if err != nil {
 		return data, err
 	}
 
 	defer row.Close()
 	for row.Next() {
-		var guildID string
-		var userID string
-		row.Scan(&guildID, &userID)
-		data = append(data, Scrub{GuildID: guildID, UserID: userID})
+		scrub := Scrub{}
+		var expiresAt sql.NullTime
+		var createdAt sql.NullTime
+		row.Scan(&scrub.ID, &scrub.GuildID, &scrub.UserID, &scrub.Emoji, &scrub.ChannelID, &scrub.AddedBy, &expiresAt, &createdAt, &scrub.Reason, &scrub.Removed)
+		if expiresAt.Valid {
+			scrub.ExpiresAt = expiresAt.Time
+		}
+		if createdAt.Valid {
+			scrub.CreatedAt = createdAt.Time
+		}
+		data = append(data, scrub)
 	}
 
 	return data, nil
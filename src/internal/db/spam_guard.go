@@ -0,0 +1,55 @@
+package db
+
+import (
+	"database/sql"
+)
+
+type SpamGuard struct {
+	Enabled bool
+	// MaxReactions - More reactions than this inside the window triggers the guard
+	MaxReactions int64
+	// WindowSeconds - Sliding window both rules look back over
+	WindowSeconds int64
+	// SameEmojiMessages - The same emoji on this many different messages inside the window triggers the guard
+	SameEmojiMessages int64
+	// ScrubMinutes - How long the automatic scrub lasts
+	ScrubMinutes    int64
+	ModLogChannelID string
+}
+
+// DefaultSpamGuard - Thresholds used until a guild changes them
+var DefaultSpamGuard = SpamGuard{
+	MaxReactions:      15,
+	WindowSeconds:     10,
+	SameEmojiMessages: 6,
+	ScrubMinutes:      30,
+}
+
+// GetSpamGuard - Spam guard settings for a guild, defaults if it hasn't been set up
+func (db *Database) GetSpamGuard(guildID string) (SpamGuard, error) {
+	guard := DefaultSpamGuard
+	err := db.db.QueryRow(
+		"SELECT spam_enabled, spam_max_reactions, spam_window_seconds, spam_same_emoji_messages, spam_scrub_minutes, modlog_channel_id "+
+			"FROM `guild_settings` WHERE `guild_id` = ?",
+		guildID,
+	).Scan(&guard.Enabled, &guard.MaxReactions, &guard.WindowSeconds, &guard.SameEmojiMessages, &guard.ScrubMinutes, &guard.ModLogChannelID)
+	if err == sql.ErrNoRows {
+		return DefaultSpamGuard, nil
+	}
+
+	return guard, err
+}
+
+// SetSpamGuard - Save spam guard settings for a guild
+func (db *Database) SetSpamGuard(guildID string, guard SpamGuard) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `guild_settings` (`guild_id`, `spam_enabled`, `spam_max_reactions`, `spam_window_seconds`, `spam_same_emoji_messages`, `spam_scrub_minutes`, `modlog_channel_id`) "+
+			"VALUES (?,?,?,?,?,?,?) ON CONFLICT(`guild_id`) DO UPDATE SET `spam_enabled` = excluded.spam_enabled, "+
+			"`spam_max_reactions` = excluded.spam_max_reactions, `spam_window_seconds` = excluded.spam_window_seconds, "+
+			"`spam_same_emoji_messages` = excluded.spam_same_emoji_messages, `spam_scrub_minutes` = excluded.spam_scrub_minutes, "+
+			"`modlog_channel_id` = excluded.modlog_channel_id",
+		guildID, guard.Enabled, guard.MaxReactions, guard.WindowSeconds, guard.SameEmojiMessages, guard.ScrubMinutes, guard.ModLogChannelID,
+	)
+
+	return err
+}
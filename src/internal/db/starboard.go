@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+type Starboard struct {
+	GuildID   string
+	ChannelID string
+	// Emoji - Emoji key, the ID for custom emojis and the name for stock ones
+	Emoji           string
+	EmojiDisplay    string
+	Threshold       int64
+	RemoveBelow     bool
+	IgnoredChannels []string
+}
+
+type StarboardEntry struct {
+	GuildID            string
+	ChannelID          string
+	MessageID          string
+	StarboardMessageID string
+	Count              int64
+}
+
+// SetStarboard - Create or replace the starboard config for a guild, keeping ignored channels
+func (db *Database) SetStarboard(guildID, channelID, emoji, emojiDisplay string, threshold int64, removeBelow bool) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `starboard` (`guild_id`, `channel_id`, `emoji`, `emoji_display`, `threshold`, `remove_below`, `ignored_channels`) VALUES (?,?,?,?,?,?,'') "+
+			"ON CONFLICT(`guild_id`) DO UPDATE SET `channel_id` = excluded.channel_id, `emoji` = excluded.emoji, "+
+			"`emoji_display` = excluded.emoji_display, `threshold` = excluded.threshold, `remove_below` = excluded.remove_below",
+		guildID, channelID, emoji, emojiDisplay, threshold, removeBelow,
+	)
+
+	return err
+}
+
+// SetStarboardIgnoredChannels - Replace the channels a guild's starboard ignores
+func (db *Database) SetStarboardIgnoredChannels(guildID string, channelIDs []string) error {
+	_, err := db.db.Exec(
+		"UPDATE `starboard` SET `ignored_channels` = ? WHERE `guild_id` = ?",
+		strings.Join(channelIDs, ","), guildID,
+	)
+
+	return err
+}
+
+// RemoveStarboard - Delete the starboard config for a guild, entries are kept in case it is re-enabled
+func (db *Database) RemoveStarboard(guildID string) error {
+	_, err := db.db.Exec(
+		"DELETE FROM `starboard` WHERE `guild_id` = ?",
+		guildID,
+	)
+
+	return err
+}
+
+// GetAllStarboards - Get all starboard configs
+func (db *Database) GetAllStarboards() ([]Starboard, error) {
+	data := make([]Starboard, 0)
+	row, err := db.db.Query("SELECT guild_id, channel_id, emoji, emoji_display, threshold, remove_below, ignored_channels FROM `starboard`")
+	if err != nil {
+		return data, err
+	}
+
+	defer row.Close()
+	for row.Next() {
+		starboard := Starboard{}
+		var ignored string
+		row.Scan(&starboard.GuildID, &starboard.ChannelID, &starboard.Emoji, &starboard.EmojiDisplay, &starboard.Threshold, &starboard.RemoveBelow, &ignored)
+		if ignored != "" {
+			starboard.IgnoredChannels = strings.Split(ignored, ",")
+		}
+		data = append(data, starboard)
+	}
+
+	return data, nil
+}
+
+// GetStarboardEntry - Starboard post for a message, false if it hasn't been posted
+func (db *Database) GetStarboardEntry(guildID, messageID string) (StarboardEntry, bool, error) {
+	entry := StarboardEntry{}
+	err := db.db.QueryRow(
+		"SELECT guild_id, channel_id, message_id, starboard_message_id, count FROM `starboard_entry` WHERE `guild_id` = ? AND `message_id` = ?",
+		guildID, messageID,
+	).Scan(&entry.GuildID, &entry.ChannelID, &entry.MessageID, &entry.StarboardMessageID, &entry.Count)
+	if err == sql.ErrNoRows {
+		return entry, false, nil
+	}
+
+	return entry, err == nil, err
+}
+
+// SetStarboardEntry - Record or update the starboard post for a message
+func (db *Database) SetStarboardEntry(entry StarboardEntry) error {
+	_, err := db.db.Exec(
+		"INSERT INTO `starboard_entry` (`guild_id`, `channel_id`, `message_id`, `starboard_message_id`, `count`) VALUES (?,?,?,?,?) "+
+			"ON CONFLICT(`guild_id`, `message_id`) DO UPDATE SET `starboard_message_id` = excluded.starboard_message_id, `count` = excluded.count",
+		entry.GuildID, entry.ChannelID, entry.MessageID, entry.StarboardMessageID, entry.Count,
+	)
+
+	return err
+}
+
+// RemoveStarboardEntry - Forget the starboard post for a message
+func (db *Database) RemoveStarboardEntry(guildID, messageID string) error {
+	_, err := db.db.Exec(
+		"DELETE FROM `starboard_entry` WHERE `guild_id` = ? AND `message_id` = ?",
+		guildID, messageID,
+	)
+
+	return err
+}